@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/robole-dev/grawler/internal/grawl"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+)
+
+var (
+	diffFlags = grawl.DiffFlags{}
+	diffCmd   = &cobra.Command{
+		Use:   "diff <old-file> <new-file>",
+		Short: "Compares the output files of two grawlings",
+		Long: `This command compares two output files (csv or jsonl) of the grawl command and reports new errors, disappeared urls, 
+slower responses, changed status codes, new urls and fixed errors. New errors, disappeared urls and slower 
+responses are regressions, which lead to the exit code 1.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			diffItUp(args[0], args[1])
+		},
+		Args: cobra.MatchAll(cobra.ExactArgs(2), cobra.OnlyValidArgs),
+	}
+)
+
+const (
+	viperDiffPrefix              = "diff"
+	flagNameDiffOutputFilepath   = "output-filepath"
+	flagNameDiffResponseErrCodes = "response-error-codes"
+	flagNameMaxSlowdown          = "max-slowdown"
+	flagNameMinSlowdown          = "min-slowdown"
+)
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffFlags.FlagOutputFilename, flagNameDiffOutputFilepath, "o", "", "Saves the diff to a csv or jsonl file, depending on the file extension.")
+	bindDiffViperFlag(flagNameDiffOutputFilepath)
+	diffCmd.Flags().StringSliceVar(&diffFlags.FlagResponseErrorCodes, flagNameDiffResponseErrCodes, []string{"400-599"}, "Status codes and status code ranges which are errors, e.g. 404,500-599.")
+	bindDiffViperFlag(flagNameDiffResponseErrCodes)
+	diffCmd.Flags().Float64Var(&diffFlags.FlagMaxSlowdown, flagNameMaxSlowdown, 50, "Responses which are slower by more than this percentage are regressions. Set it to 0 to ignore response times.")
+	bindDiffViperFlag(flagNameMaxSlowdown)
+	diffCmd.Flags().Int64Var(&diffFlags.FlagMinSlowdownMs, flagNameMinSlowdown, 100, "Responses which are slower by less than these milliseconds are no regressions.")
+	bindDiffViperFlag(flagNameMinSlowdown)
+}
+
+func diffItUp(oldPath string, newPath string) {
+
+	// Get values from viper back to flag vars
+	diffFlags.FlagOutputFilename = viper.GetString(viperDiffPrefix + "." + flagNameDiffOutputFilepath)
+	diffFlags.FlagResponseErrorCodes = viper.GetStringSlice(viperDiffPrefix + "." + flagNameDiffResponseErrCodes)
+	diffFlags.FlagMaxSlowdown = viper.GetFloat64(viperDiffPrefix + "." + flagNameMaxSlowdown)
+	diffFlags.FlagMinSlowdownMs = viper.GetInt64(viperDiffPrefix + "." + flagNameMinSlowdown)
+
+	if flagConfigInfo {
+		fmt.Println("")
+		fmt.Println("Diff configuration values")
+		fmt.Println("=========================")
+		fmt.Println("OutputFilename:", diffFlags.FlagOutputFilename)
+		fmt.Println("ResponseErrorCodes:", diffFlags.FlagResponseErrorCodes)
+		fmt.Println("MaxSlowdown:", diffFlags.FlagMaxSlowdown)
+		fmt.Println("MinSlowdownMs:", diffFlags.FlagMinSlowdownMs)
+	}
+
+	os.Exit(int(grawl.Diff(oldPath, newPath, diffFlags)))
+}
+
+func bindDiffViperFlag(flagLookup string) {
+	key := viperDiffPrefix + "." + flagLookup
+	err := viper.BindPFlag(key, diffCmd.Flags().Lookup(flagLookup))
+	if err != nil {
+		log.Fatalln(fmt.Errorf("error binding config option to flag: %v", err))
+		return
+	}
+}
@@ -2,16 +2,18 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/robole-dev/grawler/internal/grawl"
+	"github.com/robole-dev/grawler/pkg/grawler"
 	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"log"
+	"os"
+	"strings"
+	"time"
 )
 
 var (
-	grawlFlags = grawl.Flags{}
-	grawlCmd   = &cobra.Command{
+	grawlCmd = &cobra.Command{
 		Use:     "grawl",
 		Aliases: []string{"crawl"},
 		Short:   "Crawls the given url",
@@ -28,140 +30,367 @@ const (
 	viperGrawlPrefix             = "grawl"
 	flagNameDelay                = "delay"
 	flagNameRandomDelay          = "random-delay"
+	flagNameAdaptive             = "adaptive"
+	flagNameAdaptiveMaxDelay     = "adaptive-max-delay"
 	flagNameMaxDepth             = "max-depth"
+	flagNameMaxRedirects         = "max-redirects"
+	flagNameNoFollowRedirects    = "no-follow-redirects"
+	flagNameVisitRedirectTargets = "visit-redirect-targets"
 	flagNameOutputFilepath       = "output-filepath"
+	flagNameOutputFormat         = "output-format"
 	flagNameParallel             = "parallel"
 	flagNameUsername             = "username"
 	flagNamePassword             = "password"
 	flagNameUserAgent            = "user-agent"
 	flagNameSitemap              = "sitemap"
+	flagNameWarm                 = "warm"
+	flagNameWarmTwice            = "warm-twice"
+	flagNameCacheHeaders         = "cache-headers"
+	flagNameCoverage             = "coverage"
+	flagNameCoverageReport       = "coverage-report"
 	flagNameAllowedDomains       = "allowed-domains"
 	flagNameRespectRobotsTxt     = "respect-robots-txt"
 	flagNameRespectNofollow      = "respect-nofollow"
 	flagNamePath                 = "path"
 	flagNameCheckAll             = "check-all"
+	flagNameFollowElements       = "follow-elements"
+	flagNameCheckElements        = "check-elements"
 	flagNameRequestTimeout       = "request-timeout"
+	flagNameRetries              = "retries"
+	flagNameRetryDelay           = "retry-delay"
+	flagNameRetryOn              = "retry-on"
 	flagNameUrlFilters           = "url-filters"
 	flagNameDisallowedURLFilters = "disallowed-url-filters"
 	flagNameStopOnError          = "stop-on-error"
 	flagNamePauseOnError         = "pause-on-error"
+	flagNameResponseErrorCodes   = "response-error-codes"
+	flagNameFailThreshold        = "fail-threshold"
+	flagNameJunitReport          = "junit-report"
+	flagNameJunitGroupBy         = "junit-group-by"
+	flagNameHtmlReport           = "html-report"
+	flagNameSlowest              = "slowest"
+	flagNameNoStatusBar          = "no-status-bar"
+	flagNameTui                  = "tui"
+	flagNameWriteSitemap         = "write-sitemap"
+	flagNameSitemapLastmod       = "sitemap-lastmod"
+	flagNameSitemapBaseUrl       = "sitemap-base-url"
+	flagNameStateDir             = "state-dir"
+	flagNameStateInterval        = "state-interval"
+	flagNameShutdownTimeout      = "shutdown-timeout"
+	flagNameMaxDuration          = "max-duration"
+	flagNameMaxRequests          = "max-requests"
+	flagNameMaxErrors            = "max-errors"
+	configNameLimits             = "limits"
 )
 
 func init() {
-	grawlCmd.Flags().Int64VarP(&grawlFlags.FlagDelay, flagNameDelay, "d", 0, "Delay between requests in milliseconds. (default 0)")
+	grawlCmd.Flags().Int64P(flagNameDelay, "d", 0, "Delay between requests in milliseconds. (default 0)")
 	bindViperFlag(flagNameDelay)
 
-	grawlCmd.Flags().Int64Var(&grawlFlags.FlagRandomDelay, flagNameRandomDelay, 0, "Max random delay between requests in milliseconds. (default 0 for no random delay)")
+	grawlCmd.Flags().Int64(flagNameRandomDelay, 0, "Max random delay between requests in milliseconds. (default 0 for no random delay)")
 	bindViperFlag(flagNameRandomDelay)
 
-	grawlCmd.Flags().IntVarP(&grawlFlags.FlagMaxDepth, flagNameMaxDepth, "m", 0, "Set it to 0 for infinite recursion. (default 0)")
+	grawlCmd.Flags().Bool(flagNameAdaptive, false, "Slow down per host on 429 and 503 responses, errors and slow responses by reducing the parallel requests and increasing the delay. Retry-After headers are respected. The throttling recovers gradually on healthy responses.")
+	bindViperFlag(flagNameAdaptive)
+
+	grawlCmd.Flags().Int64(flagNameAdaptiveMaxDelay, 30000, "Max delay in milliseconds of the adaptive throttling, also the max wait for a Retry-After header.")
+	bindViperFlag(flagNameAdaptiveMaxDelay)
+
+	grawlCmd.Flags().IntP(flagNameMaxDepth, "m", 0, "Set it to 0 for infinite recursion. (default 0)")
 	bindViperFlag(flagNameMaxDepth)
 
-	grawlCmd.Flags().StringVarP(&grawlFlags.FlagOutputFilename, flagNameOutputFilepath, "o", "", "Write statistic data of each request to this file.")
+	grawlCmd.Flags().Int(flagNameMaxRedirects, 10, "Maximum number of redirects to follow per url. Longer redirect chains are reported as error.")
+	bindViperFlag(flagNameMaxRedirects)
+
+	grawlCmd.Flags().Bool(flagNameNoFollowRedirects, false, "Do not follow redirects. Redirect responses are reported with their own status code and location.")
+	bindViperFlag(flagNameNoFollowRedirects)
+
+	grawlCmd.Flags().Bool(flagNameVisitRedirectTargets, false, "Grawl the locations of redirects which are not followed as separate urls. Use it with --no-follow-redirects.")
+	bindViperFlag(flagNameVisitRedirectTargets)
+
+	grawlCmd.Flags().StringP(flagNameOutputFilepath, "o", "", "Write statistic data of each request to this file.")
 	bindViperFlag(flagNameOutputFilepath)
 
-	grawlCmd.Flags().IntVarP(&grawlFlags.FlagParallel, flagNameParallel, "l", 1, "Number of parallel requests.")
+	grawlCmd.Flags().String(flagNameOutputFormat, "", "Format of the output file: \"csv\" or \"jsonl\". If not set, the format is detected by the file extension (.jsonl or .ndjson for json lines, csv otherwise).")
+	bindViperFlag(flagNameOutputFormat)
+
+	grawlCmd.Flags().IntP(flagNameParallel, "l", 1, "Number of parallel requests.")
 	bindViperFlag(flagNameParallel)
 
-	grawlCmd.Flags().StringVarP(&grawlFlags.FlagUsername, flagNameUsername, "u", "", "Use this for HTTP Basic Authentication. If you omit the password-flag a prompt will ask for the password.")
+	grawlCmd.Flags().StringP(flagNameUsername, "u", "", "Use this for HTTP Basic Authentication. If you omit the password-flag a prompt will ask for the password.")
 	bindViperFlag(flagNameUsername)
 
-	grawlCmd.Flags().StringVarP(&grawlFlags.FlagPassword, flagNamePassword, "p", "", "Use this for HTTP Basic Authentication.")
+	grawlCmd.Flags().StringP(flagNamePassword, "p", "", "Use this for HTTP Basic Authentication.")
 	bindViperFlag(flagNamePassword)
 
-	grawlCmd.Flags().StringVar(&grawlFlags.FlagUserAgent, flagNameUserAgent, "grawler", "Sets the user agent.")
+	grawlCmd.Flags().String(flagNameUserAgent, "grawler", "Sets the user agent.")
 	bindViperFlag(flagNameUserAgent)
 
-	grawlCmd.Flags().BoolVarP(&grawlFlags.FlagSitemap, flagNameSitemap, "s", false, "Checks the sitemap. If this is flag is set the url parameter has to be the url to the sitemap.xml.")
+	grawlCmd.Flags().BoolP(flagNameSitemap, "s", false, "Checks the sitemap. If this is flag is set the url parameter has to be the url to the sitemap.xml or the root of the website to discover the sitemaps from its robots.txt.")
 	bindViperFlag(flagNameSitemap)
 
-	grawlCmd.Flags().StringSliceVarP(&grawlFlags.FlagAllowedDomains, flagNameAllowedDomains, "a", nil, "A comma separated list of allowed domains to be crawled. The domain of the given url is always allowed.")
+	grawlCmd.Flags().Bool(flagNameWarm, false, "Warm the caches of the website. The cache headers of the responses are saved and the cache hits and misses are summarised.")
+	bindViperFlag(flagNameWarm)
+
+	grawlCmd.Flags().Bool(flagNameWarmTwice, false, "Request each successfully grawled url a second time to compare the cold with the warm cache. Use it with --warm.")
+	bindViperFlag(flagNameWarmTwice)
+
+	grawlCmd.Flags().StringSlice(flagNameCacheHeaders, grawler.DefaultCacheHeaders, "The response headers which are saved in the warming mode.")
+	bindViperFlag(flagNameCacheHeaders)
+
+	grawlCmd.Flags().Bool(flagNameCoverage, false, "Grawls the sitemaps and the links of the website and compares the found pages. The url has to be the root of the website.")
+	bindViperFlag(flagNameCoverage)
+
+	grawlCmd.Flags().String(flagNameCoverageReport, "", "Saves the pages which are in the sitemap and found by links, only in the sitemap and only found by links to a csv file. Use it with --coverage.")
+	bindViperFlag(flagNameCoverageReport)
+
+	grawlCmd.Flags().StringSliceP(flagNameAllowedDomains, "a", nil, "A comma separated list of allowed domains to be crawled. The domain of the given url is always allowed.")
 	bindViperFlag(flagNameAllowedDomains)
 
-	grawlCmd.Flags().BoolVar(&grawlFlags.FlagRespectRobotsTxt, flagNameRespectRobotsTxt, false, "Respect the robots.txt file.")
+	grawlCmd.Flags().Bool(flagNameRespectRobotsTxt, false, "Respect the robots.txt file.")
 	bindViperFlag(flagNameRespectRobotsTxt)
 
-	grawlCmd.Flags().BoolVar(&grawlFlags.FlagRespectNofollow, flagNameRespectNofollow, false, "Respect the attribute 'rel=\"nofollow\"'")
+	grawlCmd.Flags().Bool(flagNameRespectNofollow, false, "Respect the attribute 'rel=\"nofollow\"'")
 	bindViperFlag(flagNameRespectNofollow)
 
-	grawlCmd.Flags().StringVar(&grawlFlags.FlagPath, flagNamePath, "", "Restrict the crawlings on a certain url path.")
+	grawlCmd.Flags().String(flagNamePath, "", "Restrict the crawlings on a certain url path.")
 	bindViperFlag(flagNamePath)
 
-	grawlCmd.Flags().BoolVarP(&grawlFlags.FlagCheckAll, flagNameCheckAll, "", false, "In addtion to html and xml-urls, also check image, js and css-urls, among others.")
+	grawlCmd.Flags().Bool(flagNameCheckAll, false, "In addtion to html and xml-urls, also check image, js and css-urls, among others. Checks the urls of all element types which are not followed.")
 	bindViperFlag(flagNameCheckAll)
 
-	grawlCmd.Flags().Float32Var(&grawlFlags.FlagRequestTimeout, flagNameRequestTimeout, 10, "Timeout in seconds to wait for a response.")
+	grawlCmd.Flags().StringSlice(flagNameFollowElements, grawler.DefaultFollowElements, fmt.Sprintf("Element types whose urls are grawled and searched for further links. Element types: %s", strings.Join(grawler.ElementTypes(), ", ")))
+	bindViperFlag(flagNameFollowElements)
+
+	grawlCmd.Flags().StringSlice(flagNameCheckElements, []string{}, "Element types whose urls are only requested, but not searched for further links. E.g. img,script,stylesheet")
+	bindViperFlag(flagNameCheckElements)
+
+	grawlCmd.Flags().Float32(flagNameRequestTimeout, 10, "Timeout in seconds to wait for a response.")
 	bindViperFlag(flagNameRequestTimeout)
 
-	grawlCmd.Flags().StringSliceVar(&grawlFlags.FlagURLFilters, flagNameUrlFilters, nil, "Only visit urls that match the regular expressions given here.")
+	grawlCmd.Flags().Int(flagNameRetries, 0, "Number of retries of requests which failed transiently, see --retry-on. (default 0 for no retries)")
+	bindViperFlag(flagNameRetries)
+
+	grawlCmd.Flags().Int64(flagNameRetryDelay, 1000, "Delay in milliseconds before the first retry. It is doubled for each further retry, with a random part of up to half of the delay.")
+	bindViperFlag(flagNameRetryDelay)
+
+	grawlCmd.Flags().StringSlice(flagNameRetryOn, grawler.DefaultRetryOn, "The failures which are retried: \"timeout\", \"connection\" for refused or reset connections and http status codes (e.g. 429) or ranges (e.g. 502-504).")
+	bindViperFlag(flagNameRetryOn)
+
+	grawlCmd.Flags().StringSlice(flagNameUrlFilters, nil, "Only visit urls that match the regular expressions given here.")
 	bindViperFlag(flagNameUrlFilters)
 
-	grawlCmd.Flags().StringSliceVar(&grawlFlags.FlagDisallowedURLFilters, flagNameDisallowedURLFilters, nil, "Do not visit urls that match the regular expressions given here.")
+	grawlCmd.Flags().StringSlice(flagNameDisallowedURLFilters, nil, "Do not visit urls that match the regular expressions given here.")
 	bindViperFlag(flagNameDisallowedURLFilters)
 
-	grawlCmd.Flags().BoolVar(&grawlFlags.FlagStopOnError, flagNameStopOnError, false, "The grawling stops on errors.")
+	grawlCmd.Flags().Bool(flagNameStopOnError, false, "The grawling stops on errors.")
 	bindViperFlag(flagNameStopOnError)
 
-	grawlCmd.Flags().BoolVar(&grawlFlags.FlagPauseOnError, flagNamePauseOnError, false, "The grawling pauses on errors and you have the option to cancel, skip or try again.")
+	grawlCmd.Flags().Bool(flagNamePauseOnError, false, "The grawling pauses on errors and you have the option to abort, skip, retry or ignore the status or the path of the error.")
 	bindViperFlag(flagNamePauseOnError)
 
-	//grawlCmd.Flags().StringSliceVar(&grawlFlags.FlagResponseErrorCodes, flagNameResponseErrorCodes, []string{"400-599"}, "The http error codes that are evaluated as errors. You can define multiple single vales or multiple value ranges.")
-	//bindViperFlag(flagNameResponseErrorCodes)
+	grawlCmd.Flags().StringSlice(flagNameResponseErrorCodes, []string{"400-599"}, "The http status codes that are evaluated as errors. You can define multiple single values (e.g. 404) or value ranges (e.g. 500-599).")
+	bindViperFlag(flagNameResponseErrorCodes)
+
+	grawlCmd.Flags().String(flagNameFailThreshold, "1", "Exit with an error code if the number of errors reaches this threshold. Use a number (e.g. 10) or a percentage of all requests (e.g. 5%). Set it to 0 to never fail on errors.")
+	bindViperFlag(flagNameFailThreshold)
+
+	grawlCmd.Flags().String(flagNameJunitReport, "", "Write a JUnit XML report to this file. Each url is a test case, urls with errors are failures.")
+	bindViperFlag(flagNameJunitReport)
+
+	grawlCmd.Flags().String(flagNameJunitGroupBy, grawler.JunitGroupByHost, "Group the test cases of the JUnit report into test suites by \"host\" or by the \"found-on\" url.")
+	bindViperFlag(flagNameJunitGroupBy)
+
+	grawlCmd.Flags().String(flagNameHtmlReport, "", "Write a self-contained html report with statistics and all results to this file.")
+	bindViperFlag(flagNameHtmlReport)
+
+	grawlCmd.Flags().Int(flagNameSlowest, 10, "Number of the slowest urls in the summary. Set it to 0 to hide the slowest urls.")
+	bindViperFlag(flagNameSlowest)
+
+	grawlCmd.Flags().Bool(flagNameNoStatusBar, false, "Do not show the live status bar at the bottom of the terminal. It is not shown if the output is not a terminal.")
+	bindViperFlag(flagNameNoStatusBar)
+
+	grawlCmd.Flags().Bool(flagNameTui, false, "Explore the running grawling in an interactive terminal ui with panes for the results, errors, hosts and the frontier.")
+	bindViperFlag(flagNameTui)
+
+	grawlCmd.Flags().String(flagNameWriteSitemap, "", "Write a sitemap with all successfully grawled html pages to this file. Redirected, errored, noindex and canonicalised pages are excluded.")
+	bindViperFlag(flagNameWriteSitemap)
+
+	grawlCmd.Flags().Bool(flagNameSitemapLastmod, false, "Add the Last-Modified header of the pages as lastmod to the written sitemap. Use it with --write-sitemap.")
+	bindViperFlag(flagNameSitemapLastmod)
+
+	grawlCmd.Flags().String(flagNameSitemapBaseUrl, "", "The url under which the split sitemaps of a written sitemap index are published, e.g. https://example.com/sitemaps/. (default the root of the grawled website)")
+	bindViperFlag(flagNameSitemapBaseUrl)
+
+	grawlCmd.Flags().String(flagNameStateDir, "", "Save the state of the grawling periodically and on interrupt to this directory. Use \"grawler resume <state-dir>\" to continue an interrupted grawling.")
+	bindViperFlag(flagNameStateDir)
+
+	grawlCmd.Flags().Int(flagNameStateInterval, 30, "Interval in seconds to save the state of the grawling.")
+	bindViperFlag(flagNameStateInterval)
+
+	grawlCmd.Flags().Float32(flagNameShutdownTimeout, 10, "Timeout in seconds to wait for running requests after the grawling has been interrupted.")
+	bindViperFlag(flagNameShutdownTimeout)
+
+	grawlCmd.Flags().Duration(flagNameMaxDuration, 0, "Stop the grawling after this duration, e.g. 15m. Running requests are finished and the summary is marked as truncated by budget. (default 0 for no limit)")
+	bindViperFlag(flagNameMaxDuration)
+
+	grawlCmd.Flags().Int(flagNameMaxRequests, 0, "Stop the grawling after this number of requests, retries included. Running requests are finished and the summary is marked as truncated by budget. (default 0 for no limit)")
+	bindViperFlag(flagNameMaxRequests)
+
+	grawlCmd.Flags().Int(flagNameMaxErrors, 0, "Stop the grawling after this number of errors. Running requests are finished and the summary is marked as truncated by budget. (default 0 for no limit)")
+	bindViperFlag(flagNameMaxErrors)
+
+	// Limits per host can only be set in the config file
+	viper.SetDefault(viperGrawlPrefix+"."+configNameLimits, []grawler.LimitRule{})
 }
 
 func warmItUp(url string) {
 
-	// Get values from viper back to flag vars
-	grawlFlags.FlagDelay = viper.GetInt64(viperGrawlPrefix + "." + flagNameDelay)
-	grawlFlags.FlagRandomDelay = viper.GetInt64(viperGrawlPrefix + "." + flagNameRandomDelay)
-	grawlFlags.FlagMaxDepth = viper.GetInt(viperGrawlPrefix + "." + flagNameMaxDepth)
-	grawlFlags.FlagOutputFilename = viper.GetString(viperGrawlPrefix + "." + flagNameOutputFilepath)
-	grawlFlags.FlagParallel = viper.GetInt(viperGrawlPrefix + "." + flagNameParallel)
-	grawlFlags.FlagUsername = viper.GetString(viperGrawlPrefix + "." + flagNameUsername)
-	grawlFlags.FlagPassword = viper.GetString(viperGrawlPrefix + "." + flagNamePassword)
-	grawlFlags.FlagUserAgent = viper.GetString(viperGrawlPrefix + "." + flagNameUserAgent)
-	grawlFlags.FlagSitemap = viper.GetBool(viperGrawlPrefix + "." + flagNameSitemap)
-	grawlFlags.FlagAllowedDomains = viper.GetStringSlice(viperGrawlPrefix + "." + flagNameAllowedDomains)
-	grawlFlags.FlagRespectRobotsTxt = viper.GetBool(viperGrawlPrefix + "." + flagNameRespectRobotsTxt)
-	grawlFlags.FlagRespectNofollow = viper.GetBool(viperGrawlPrefix + "." + flagNameRespectNofollow)
-	grawlFlags.FlagPath = viper.GetString(viperGrawlPrefix + "." + flagNamePath)
-	grawlFlags.FlagCheckAll = viper.GetBool(viperGrawlPrefix + "." + flagNameCheckAll)
-	grawlFlags.FlagRequestTimeout = cast.ToFloat32(viper.Get(viperGrawlPrefix + "." + flagNameRequestTimeout))
-	grawlFlags.FlagURLFilters = viper.GetStringSlice(viperGrawlPrefix + "." + flagNameUrlFilters)
-	grawlFlags.FlagDisallowedURLFilters = viper.GetStringSlice(viperGrawlPrefix + "." + flagNameDisallowedURLFilters)
-	grawlFlags.FlagStopOnError = viper.GetBool(viperGrawlPrefix + "." + flagNameStopOnError)
-	grawlFlags.FlagPauseOnError = viper.GetBool(viperGrawlPrefix + "." + flagNamePauseOnError)
-	//grawlFlags.FlagResponseErrorCodes = viper.GetStringSlice(viperGrawlPrefix + "." + flagNameResponseErrorCodes)
+	// Get values from viper into the config of the grawling
+	config := grawler.Config{
+		Parallel:             grawler.Ptr(viper.GetInt(viperGrawlPrefix + "." + flagNameParallel)),
+		Delay:                viperMilliseconds(flagNameDelay),
+		RandomDelay:          viperMilliseconds(flagNameRandomDelay),
+		Adaptive:             viper.GetBool(viperGrawlPrefix + "." + flagNameAdaptive),
+		AdaptiveMaxDelay:     grawler.Ptr(viperMilliseconds(flagNameAdaptiveMaxDelay)),
+		MaxDepth:             viper.GetInt(viperGrawlPrefix + "." + flagNameMaxDepth),
+		MaxRedirects:         viper.GetInt(viperGrawlPrefix + "." + flagNameMaxRedirects),
+		NoFollowRedirects:    viper.GetBool(viperGrawlPrefix + "." + flagNameNoFollowRedirects),
+		VisitRedirectTargets: viper.GetBool(viperGrawlPrefix + "." + flagNameVisitRedirectTargets),
+		UserAgent:            viper.GetString(viperGrawlPrefix + "." + flagNameUserAgent),
+		Username:             viper.GetString(viperGrawlPrefix + "." + flagNameUsername),
+		Password:             viper.GetString(viperGrawlPrefix + "." + flagNamePassword),
+		AllowedDomains:       viper.GetStringSlice(viperGrawlPrefix + "." + flagNameAllowedDomains),
+		URLFilters:           viper.GetStringSlice(viperGrawlPrefix + "." + flagNameUrlFilters),
+		DisallowedURLFilters: viper.GetStringSlice(viperGrawlPrefix + "." + flagNameDisallowedURLFilters),
+		RespectRobotsTxt:     viper.GetBool(viperGrawlPrefix + "." + flagNameRespectRobotsTxt),
+		RespectNofollow:      viper.GetBool(viperGrawlPrefix + "." + flagNameRespectNofollow),
+		Path:                 viper.GetString(viperGrawlPrefix + "." + flagNamePath),
+		Sitemap:              viper.GetBool(viperGrawlPrefix + "." + flagNameSitemap),
+		FollowElements:       viper.GetStringSlice(viperGrawlPrefix + "." + flagNameFollowElements),
+		CheckElements:        viper.GetStringSlice(viperGrawlPrefix + "." + flagNameCheckElements),
+		CheckAll:             viper.GetBool(viperGrawlPrefix + "." + flagNameCheckAll),
+		RequestTimeout:       grawler.Ptr(viperSeconds(flagNameRequestTimeout)),
+		Retries:              viper.GetInt(viperGrawlPrefix + "." + flagNameRetries),
+		RetryDelay:           grawler.Ptr(viperMilliseconds(flagNameRetryDelay)),
+		RetryOn:              viper.GetStringSlice(viperGrawlPrefix + "." + flagNameRetryOn),
+		ResponseErrorCodes:   viper.GetStringSlice(viperGrawlPrefix + "." + flagNameResponseErrorCodes),
+		StopOnError:          viper.GetBool(viperGrawlPrefix + "." + flagNameStopOnError),
+		OutputFile:           viper.GetString(viperGrawlPrefix + "." + flagNameOutputFilepath),
+		OutputFormat:         viper.GetString(viperGrawlPrefix + "." + flagNameOutputFormat),
+		Warm:                 viper.GetBool(viperGrawlPrefix + "." + flagNameWarm),
+		WarmTwice:            viper.GetBool(viperGrawlPrefix + "." + flagNameWarmTwice),
+		CacheHeaders:         viper.GetStringSlice(viperGrawlPrefix + "." + flagNameCacheHeaders),
+		ShutdownTimeout:      grawler.Ptr(viperSeconds(flagNameShutdownTimeout)),
+		MaxDuration:          viper.GetDuration(viperGrawlPrefix + "." + flagNameMaxDuration),
+		MaxRequests:          viper.GetInt(viperGrawlPrefix + "." + flagNameMaxRequests),
+		MaxErrors:            viper.GetInt(viperGrawlPrefix + "." + flagNameMaxErrors),
+		FailThreshold:        viper.GetString(viperGrawlPrefix + "." + flagNameFailThreshold),
+		PauseOnError:         viper.GetBool(viperGrawlPrefix + "." + flagNamePauseOnError),
+		Slowest:              viper.GetInt(viperGrawlPrefix + "." + flagNameSlowest),
+		StatusBar:            !viper.GetBool(viperGrawlPrefix + "." + flagNameNoStatusBar),
+		Tui:                  viper.GetBool(viperGrawlPrefix + "." + flagNameTui),
+		JunitReport:          viper.GetString(viperGrawlPrefix + "." + flagNameJunitReport),
+		JunitGroupBy:         viper.GetString(viperGrawlPrefix + "." + flagNameJunitGroupBy),
+		HtmlReport:           viper.GetString(viperGrawlPrefix + "." + flagNameHtmlReport),
+		WriteSitemap:         viper.GetString(viperGrawlPrefix + "." + flagNameWriteSitemap),
+		SitemapLastmod:       viper.GetBool(viperGrawlPrefix + "." + flagNameSitemapLastmod),
+		SitemapBaseUrl:       viper.GetString(viperGrawlPrefix + "." + flagNameSitemapBaseUrl),
+		CoverageReport:       viper.GetString(viperGrawlPrefix + "." + flagNameCoverageReport),
+		StateDir:             viper.GetString(viperGrawlPrefix + "." + flagNameStateDir),
+		StateInterval:        time.Duration(viper.GetInt(viperGrawlPrefix+"."+flagNameStateInterval)) * time.Second,
+	}
+	if err := viper.UnmarshalKey(viperGrawlPrefix+"."+configNameLimits, &config.Limits); err != nil {
+		fmt.Println("Invalid configuration of limits:", err)
+		os.Exit(int(grawler.ExitCodeConfigError))
+	}
+	coverage := viper.GetBool(viperGrawlPrefix + "." + flagNameCoverage)
 
 	if flagConfigInfo {
 		fmt.Println("")
 		fmt.Println("Grawl configuration values")
 		fmt.Println("==========================")
 		fmt.Println("Url:", url)
-		fmt.Println("Delay:", grawlFlags.FlagDelay)
-		fmt.Println("RandomDelay:", grawlFlags.FlagRandomDelay)
-		fmt.Println("MaxDepth:", grawlFlags.FlagMaxDepth)
-		fmt.Println("OutputFilepath:", grawlFlags.FlagOutputFilename)
-		fmt.Println("Parallel:", grawlFlags.FlagParallel)
-		fmt.Println("Username:", grawlFlags.FlagUsername)
-		fmt.Println("Password:", grawlFlags.FlagPassword)
-		fmt.Println("UserAgent:", grawlFlags.FlagUserAgent)
-		fmt.Println("Sitemap:", grawlFlags.FlagSitemap)
-		fmt.Println("AllowedDomains:", grawlFlags.FlagAllowedDomains)
-		fmt.Println("RespectRobotsTxt:", grawlFlags.FlagRespectRobotsTxt)
-		fmt.Println("RespectNofollow:", grawlFlags.FlagRespectNofollow)
-		fmt.Println("Path:", grawlFlags.FlagPath)
-		fmt.Println("CheckAll:", grawlFlags.FlagCheckAll)
-		fmt.Println("RequestTimeout:", grawlFlags.FlagRequestTimeout)
-		fmt.Println("URLFilters:", grawlFlags.FlagURLFilters)
-		fmt.Println("DisallowedURLFilters:", grawlFlags.FlagDisallowedURLFilters)
-		fmt.Println("StopOnError:", grawlFlags.FlagStopOnError)
-		fmt.Println("PauseOnError:", grawlFlags.FlagPauseOnError)
-		//fmt.Println("HttpErrorCodes:", grawlFlags.FlagResponseErrorCodes)
+		fmt.Println("Delay:", config.Delay)
+		fmt.Println("RandomDelay:", config.RandomDelay)
+		fmt.Println("Adaptive:", config.Adaptive)
+		fmt.Println("AdaptiveMaxDelay:", *config.AdaptiveMaxDelay)
+		fmt.Println("MaxDepth:", config.MaxDepth)
+		fmt.Println("MaxRedirects:", config.MaxRedirects)
+		fmt.Println("NoFollowRedirects:", config.NoFollowRedirects)
+		fmt.Println("VisitRedirectTargets:", config.VisitRedirectTargets)
+		fmt.Println("OutputFilepath:", config.OutputFile)
+		fmt.Println("OutputFormat:", config.OutputFormat)
+		fmt.Println("Parallel:", *config.Parallel)
+		fmt.Println("Username:", config.Username)
+		fmt.Println("Password:", config.Password)
+		fmt.Println("UserAgent:", config.UserAgent)
+		fmt.Println("Sitemap:", config.Sitemap)
+		fmt.Println("Warm:", config.Warm)
+		fmt.Println("WarmTwice:", config.WarmTwice)
+		fmt.Println("CacheHeaders:", config.CacheHeaders)
+		fmt.Println("Coverage:", coverage)
+		fmt.Println("CoverageReport:", config.CoverageReport)
+		fmt.Println("AllowedDomains:", config.AllowedDomains)
+		fmt.Println("RespectRobotsTxt:", config.RespectRobotsTxt)
+		fmt.Println("RespectNofollow:", config.RespectNofollow)
+		fmt.Println("Path:", config.Path)
+		fmt.Println("CheckAll:", config.CheckAll)
+		fmt.Println("FollowElements:", config.FollowElements)
+		fmt.Println("CheckElements:", config.CheckElements)
+		fmt.Println("RequestTimeout:", *config.RequestTimeout)
+		fmt.Println("Retries:", config.Retries)
+		fmt.Println("RetryDelay:", *config.RetryDelay)
+		fmt.Println("RetryOn:", config.RetryOn)
+		fmt.Println("URLFilters:", config.URLFilters)
+		fmt.Println("DisallowedURLFilters:", config.DisallowedURLFilters)
+		fmt.Println("StopOnError:", config.StopOnError)
+		fmt.Println("PauseOnError:", config.PauseOnError)
+		fmt.Println("ResponseErrorCodes:", config.ResponseErrorCodes)
+		fmt.Println("FailThreshold:", config.FailThreshold)
+		fmt.Println("JunitReport:", config.JunitReport)
+		fmt.Println("JunitGroupBy:", config.JunitGroupBy)
+		fmt.Println("HtmlReport:", config.HtmlReport)
+		fmt.Println("Slowest:", config.Slowest)
+		fmt.Println("StatusBar:", config.StatusBar)
+		fmt.Println("Tui:", config.Tui)
+		fmt.Println("WriteSitemap:", config.WriteSitemap)
+		fmt.Println("SitemapLastmod:", config.SitemapLastmod)
+		fmt.Println("SitemapBaseUrl:", config.SitemapBaseUrl)
+		fmt.Println("StateDir:", config.StateDir)
+		fmt.Println("StateInterval:", config.StateInterval)
+		fmt.Println("ShutdownTimeout:", *config.ShutdownTimeout)
+		fmt.Println("MaxDuration:", config.MaxDuration)
+		fmt.Println("MaxRequests:", config.MaxRequests)
+		fmt.Println("MaxErrors:", config.MaxErrors)
+		if limitRules, err := config.EffectiveLimitRules(); err == nil {
+			fmt.Println("Limits:")
+			for _, rule := range limitRules {
+				fmt.Println("  -", rule)
+			}
+		}
+	}
+
+	crawler, err := grawler.New(config)
+	if err != nil {
+		fmt.Println("Invalid configuration:", err)
+		os.Exit(int(grawler.ExitCodeConfigError))
 	}
 
-	grawler := grawl.NewGrawler(grawlFlags)
-	grawler.Grawl(url)
+	if coverage {
+		os.Exit(int(crawler.Coverage(url)))
+	}
+	os.Exit(int(crawler.Run(url)))
+}
+
+// viperMilliseconds returns the value of a flag in milliseconds as duration
+func viperMilliseconds(flagLookup string) time.Duration {
+	return time.Duration(viper.GetInt64(viperGrawlPrefix+"."+flagLookup)) * time.Millisecond
+}
+
+// viperSeconds returns the value of a flag in seconds, which may have a fraction, as duration
+func viperSeconds(flagLookup string) time.Duration {
+	return time.Duration(cast.ToFloat64(viper.Get(viperGrawlPrefix+"."+flagLookup)) * float64(time.Second))
 }
 
 func bindViperFlag(flagLookup string) {
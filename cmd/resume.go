@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/robole-dev/grawler/pkg/grawler"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	resumeCmd = &cobra.Command{
+		Use:   "resume <state-dir>",
+		Short: "Resumes an interrupted grawling",
+		Long:  `This command continues a grawling that has been started with the flag "--state-dir" with the same configuration.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			resumeGrawling(args[0])
+		},
+		Args: cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	}
+)
+
+func resumeGrawling(stateDir string) {
+	state, err := grawler.LoadState(stateDir)
+	if err != nil {
+		fmt.Println("Could not resume grawling:", err)
+		os.Exit(int(grawler.ExitCodeConfigError))
+	}
+
+	if flagConfigInfo {
+		fmt.Println("")
+		fmt.Println("Resumed grawl configuration values")
+		fmt.Println("==================================")
+		fmt.Println("Url:", state.StartURL)
+		fmt.Println("StateDir:", state.StateDir)
+		fmt.Println("SavedAt:", state.SavedAt)
+	}
+
+	exitCode, err := state.Resume(nil)
+	if err != nil {
+		fmt.Println("Invalid configuration:", err)
+	}
+	os.Exit(int(exitCode))
+}
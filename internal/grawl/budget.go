@@ -0,0 +1,69 @@
+package grawl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// budgetError is the budget of --max-duration, --max-requests or --max-errors which truncated the grawling
+type budgetError struct {
+	budget string
+	limit  string
+}
+
+func (e *budgetError) Error() string {
+	return fmt.Sprintf("%s of %s reached", e.budget, e.limit)
+}
+
+// stopByBudget stops the grawling like an interrupt, but the exit code is evaluated as if the grawling had finished
+func (g *Grawler) stopByBudget(err *budgetError) {
+	g.stopOnce.Do(func() {
+		g.exhaustedBudget = err
+		g.stopGrawling(ExitCodeOk, fmt.Sprintf("Grawling truncated by budget: %s. Waiting for running requests to finish.", err))
+	})
+}
+
+// truncatedByBudget returns the budget which stopped the grawling or nil
+func (g *Grawler) truncatedByBudget() *budgetError {
+	if !g.isStopping() {
+		return nil
+	}
+	return g.exhaustedBudget
+}
+
+// withDurationBudget returns a context which is canceled with a budgetError when --max-duration is over
+func (g *Grawler) withDurationBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.flags.FlagMaxDuration <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, g.flags.FlagMaxDuration, &budgetError{budget: "max duration", limit: g.flags.FlagMaxDuration.String()})
+}
+
+// takeRequestBudget counts a request against --max-requests. The request which uses up the budget is still sent
+// and stops the grawling, it returns false for all further requests.
+func (g *Grawler) takeRequestBudget() bool {
+	if g.flags.FlagMaxRequests <= 0 {
+		return true
+	}
+
+	count := g.budgetRequests.Add(1)
+	if count > int64(g.flags.FlagMaxRequests) {
+		return false
+	}
+	if count == int64(g.flags.FlagMaxRequests) {
+		g.stopByBudget(&budgetError{budget: "max requests", limit: strconv.Itoa(g.flags.FlagMaxRequests)})
+	}
+	return true
+}
+
+// countErrorBudget counts an error against --max-errors and stops the grawling when the budget is used up
+func (g *Grawler) countErrorBudget(result *Result) {
+	if g.flags.FlagMaxErrors <= 0 || !result.HasError() {
+		return
+	}
+
+	if g.budgetErrors.Add(1) == int64(g.flags.FlagMaxErrors) {
+		g.stopByBudget(&budgetError{budget: "max errors", limit: strconv.Itoa(g.flags.FlagMaxErrors)})
+	}
+}
@@ -0,0 +1,133 @@
+package grawl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBudgetTestServer serves a start page which links 20 pages. The pages respond with the status code after
+// the delay, the server counts the requests.
+func newBudgetTestServer(t *testing.T, statusCode int, delay time.Duration, requests *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>")
+		for i := 1; i <= 20; i++ {
+			fmt.Fprintf(w, `<a href="/pages/%d">page</a>`, i)
+		}
+		fmt.Fprint(w, "</body></html>")
+	})
+	mux.HandleFunc("/pages/", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(statusCode)
+		fmt.Fprint(w, "<html><body></body></html>")
+	})
+
+	return server
+}
+
+func TestBudgets(t *testing.T) {
+	tests := []struct {
+		name             string
+		flags            Flags
+		statusCode       int
+		delay            time.Duration
+		expectedErr      string
+		expectedRequests func(requests int64) bool
+		expectedExitCode ExitCode
+	}{
+		{
+			name:             "no budget",
+			flags:            Flags{},
+			statusCode:       http.StatusOK,
+			expectedRequests: func(requests int64) bool { return requests == 21 },
+			expectedExitCode: ExitCodeOk,
+		},
+		{
+			name:             "max requests",
+			flags:            Flags{FlagMaxRequests: 5},
+			statusCode:       http.StatusOK,
+			expectedErr:      "max requests of 5 reached",
+			expectedRequests: func(requests int64) bool { return requests == 5 },
+			expectedExitCode: ExitCodeOk,
+		},
+		{
+			name:             "max errors",
+			flags:            Flags{FlagMaxErrors: 3, FlagFailThreshold: "0"},
+			statusCode:       http.StatusInternalServerError,
+			expectedErr:      "max errors of 3 reached",
+			expectedRequests: func(requests int64) bool { return requests >= 4 && requests < 21 },
+			expectedExitCode: ExitCodeOk,
+		},
+		{
+			name:             "max errors over the fail threshold",
+			flags:            Flags{FlagMaxErrors: 3},
+			statusCode:       http.StatusInternalServerError,
+			expectedErr:      "max errors of 3 reached",
+			expectedRequests: func(requests int64) bool { return requests >= 4 && requests < 21 },
+			expectedExitCode: ExitCodeErrorsFound,
+		},
+		{
+			name:             "max duration",
+			flags:            Flags{FlagMaxDuration: 300 * time.Millisecond},
+			statusCode:       http.StatusOK,
+			delay:            50 * time.Millisecond,
+			expectedErr:      "max duration of 300ms reached",
+			expectedRequests: func(requests int64) bool { return requests > 1 && requests < 21 },
+			expectedExitCode: ExitCodeOk,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int64
+			server := newBudgetTestServer(t, test.statusCode, test.delay, &requests)
+
+			flags := test.flags
+			flags.FlagParallel = 1
+			flags.FlagRequestTimeout = 5
+			flags.FlagShutdownTimeout = 5
+			flags.FlagNoStatusBar = true
+			grawler, err := NewGrawler(flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			grawler.SetOutput(io.Discard)
+
+			var finishedErr error
+			grawler.OnEvent(func(event Event) {
+				if event.Type == EventFinished {
+					finishedErr = event.Err
+				}
+			})
+
+			if exitCode := grawler.Grawl(server.URL + "/"); exitCode != test.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d", test.expectedExitCode, exitCode)
+			}
+
+			if test.expectedErr == "" && finishedErr != nil {
+				t.Errorf("expected no budget error, got %v", finishedErr)
+			}
+			if test.expectedErr != "" && (finishedErr == nil || !strings.Contains(finishedErr.Error(), test.expectedErr)) {
+				t.Errorf("expected budget error %q, got %v", test.expectedErr, finishedErr)
+			}
+			if !test.expectedRequests(requests.Load()) {
+				t.Errorf("unexpected number of requests %d", requests.Load())
+			}
+		})
+	}
+}
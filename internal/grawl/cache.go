@@ -0,0 +1,257 @@
+package grawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CacheStatusHit  = "HIT"
+	CacheStatusMiss = "MISS"
+
+	cacheHeadersDivider = " | "
+)
+
+var DefaultCacheHeaders = []string{"X-Cache", "CF-Cache-Status", "Age", "X-Varnish", "Cache-Control"}
+
+// recordCacheHeaders saves the configured cache headers of the response and derives the cache status
+func (r *Result) recordCacheHeaders(headerNames []string, headers http.Header) {
+	r.cacheHeaders = nil
+	for _, name := range headerNames {
+		if value := headers.Get(name); value != "" {
+			r.cacheHeaders = append(r.cacheHeaders, http.CanonicalHeaderKey(name)+": "+value)
+		}
+	}
+	r.cacheStatus = cacheStatus(headers)
+}
+
+// GetCacheHeaders returns the recorded cache headers, e.g. "X-Cache: HIT | Age: 120"
+func (r *Result) GetCacheHeaders() string {
+	return strings.Join(r.cacheHeaders, cacheHeadersDivider)
+}
+
+// cacheStatus derives HIT or MISS from the headers of common caches and CDNs. Other statuses of the
+// headers like "EXPIRED" or "BYPASS" are returned as they are, an unknown status is empty.
+func cacheStatus(headers http.Header) string {
+	for _, name := range []string{"CF-Cache-Status", "X-Cache-Status", "X-Cache", "X-Proxy-Cache"} {
+		if value := headers.Get(name); value != "" {
+			return normalizeCacheStatus(value)
+		}
+	}
+
+	// Varnish adds the id of the cached request to the id of the current request on a hit
+	if varnish := headers.Get("X-Varnish"); varnish != "" {
+		if len(strings.Fields(varnish)) > 1 {
+			return CacheStatusHit
+		}
+		return CacheStatusMiss
+	}
+
+	if age, err := strconv.Atoi(strings.TrimSpace(headers.Get("Age"))); err == nil {
+		if age > 0 {
+			return CacheStatusHit
+		}
+		return CacheStatusMiss
+	}
+
+	return ""
+}
+
+// normalizeCacheStatus returns the status of the cache nearest to the client, e.g. "MISS" of "HIT, MISS"
+// or "HIT" of "Hit from cloudfront"
+func normalizeCacheStatus(value string) string {
+	layers := strings.Split(value, ",")
+	fields := strings.Fields(layers[len(layers)-1])
+	if len(fields) == 0 {
+		return ""
+	}
+
+	status := strings.ToUpper(fields[0])
+	switch {
+	case strings.HasPrefix(status, "HIT"):
+		return CacheStatusHit
+	case strings.HasPrefix(status, "MISS"):
+		return CacheStatusMiss
+	}
+	return status
+}
+
+// warmRequestKey marks the context of the second requests of --warm-twice
+type warmRequestKey struct{}
+
+const warmRequestCtxKey = "warmRequest"
+
+// warmRequest is the first request of a url which is requested a second time
+type warmRequest struct {
+	result  *Result
+	request *colly.Request
+}
+
+// newWarmCollector creates the collector of the second requests. It shares the http backend of the collector of the
+// grawling, so the second requests go through the same transport, limits and delays and count for the budgets.
+func (g *Grawler) newWarmCollector(c *colly.Collector) *colly.Collector {
+	warm := c.Clone()
+	warm.Context = context.WithValue(c.Context, warmRequestKey{}, true)
+	warm.AllowURLRevisit = true
+	warm.MaxDepth = 0
+	// The urls have passed the filters already, the last url of a redirect chain may not match them
+	warm.AllowedDomains = nil
+	warm.URLFilters = nil
+	warm.DisallowedURLFilters = nil
+	// The trace measures the time to the headers like the duration of the first request
+	warm.TraceHTTP = true
+
+	warm.OnRequest(func(r *colly.Request) {
+		if !g.admitRequest(r.URL.String(), r.URL.Host, true) {
+			r.Abort()
+			warm := r.Ctx.GetAny(warmRequestCtxKey).(*warmRequest)
+			g.completeResult(warm.result, warm.request)
+		}
+	})
+	warm.OnResponse(func(r *colly.Response) {
+		g.finishWarm(r, nil)
+	})
+	warm.OnError(g.finishWarm)
+	return warm
+}
+
+func isWarmRequest(req *http.Request) bool {
+	warm, _ := req.Context().Value(warmRequestKey{}).(bool)
+	return warm
+}
+
+// warmRoundTrip sends a second request. It is paused, throttled and budgeted like the other requests, but does not
+// change the result of the first request.
+func (g *Grawler) warmRoundTrip(req *http.Request) (*http.Response, error) {
+	if err := g.checkSendRequest(); err != nil {
+		return nil, err
+	}
+
+	throttle := g.takeAdmittedThrottle(req.URL.String(), true)
+	start := time.Now()
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if throttle != nil {
+		throttle.update(res, err, time.Since(start))
+	}
+	return res, err
+}
+
+// warmAgain queues a second request of a successfully grawled url to compare the cold with the warm cache. The result
+// is completed when the second response arrived. It returns false if the url is not requested again.
+func (g *Grawler) warmAgain(result *Result, request *colly.Request) bool {
+	if result.HasError() || result.statusCode < 200 || result.statusCode >= 300 || g.isStopping() {
+		return false
+	}
+
+	ctx := colly.NewContext()
+	ctx.Put(warmRequestCtxKey, &warmRequest{result: result, request: request})
+	headers := http.Header{}
+	if g.headerAuth != "" {
+		headers.Set("Authorization", g.headerAuth)
+	}
+
+	if err := g.warmCollector.Request(http.MethodGet, result.url, nil, ctx, headers); err != nil {
+		fmt.Fprintf(g.out(), "Could not warm %s again: %v\n", result.url, err)
+		return false
+	}
+	return true
+}
+
+// finishWarm records the second response of a url and completes the result of the first request
+func (g *Grawler) finishWarm(r *colly.Response, err error) {
+	warm := r.Ctx.GetAny(warmRequestCtxKey).(*warmRequest)
+	result := warm.result
+	g.releaseAdmittedThrottle(r.Request.URL.String(), true)
+
+	if errors.Is(err, errRequestPaused) {
+		if retryErr := r.Request.Retry(); retryErr == nil {
+			return
+		}
+	}
+
+	if r.StatusCode == 0 {
+		if !errors.Is(err, errGrawlingStopped) {
+			fmt.Fprintf(g.out(), "Could not warm %s again: %v\n", result.url, err)
+		}
+	} else {
+		result.warmed = true
+		result.warmStatusCode = r.StatusCode
+		result.warmCacheStatus = cacheStatus(*r.Headers)
+		if r.Trace != nil {
+			result.warmDuration = r.Trace.FirstByteDuration
+		}
+	}
+	g.completeResult(result, warm.request)
+}
+
+// cacheSummary counts the cache statuses of the first and the second request of each url
+type cacheSummary struct {
+	statuses     map[string]int
+	warmStatuses map[string]int
+	warmed       int
+	coldDuration []time.Duration
+	warmDuration []time.Duration
+}
+
+func newCacheSummary(results []*Result) cacheSummary {
+	summary := cacheSummary{statuses: map[string]int{}, warmStatuses: map[string]int{}}
+	for _, result := range results {
+		summary.statuses[result.cacheStatus]++
+		if !result.warmed {
+			continue
+		}
+		summary.warmed++
+		summary.warmStatuses[result.warmCacheStatus]++
+		summary.coldDuration = append(summary.coldDuration, result.GetDuration())
+		summary.warmDuration = append(summary.warmDuration, result.warmDuration)
+	}
+	return summary
+}
+
+func printCacheStatuses(out io.Writer, title string, statuses map[string]int) {
+	total := 0
+	for _, count := range statuses {
+		total += count
+	}
+
+	ratio := func(count int) string {
+		if total == 0 {
+			return "0%"
+		}
+		return strconv.FormatFloat(float64(count)*100/float64(total), 'f', 1, 64) + "%"
+	}
+
+	other := total - statuses[CacheStatusHit] - statuses[CacheStatusMiss] - statuses[""]
+	fmt.Fprintf(out, "%-22s%d\n", title, total)
+	fmt.Fprintf(out, "  - Hits:             %d (%s)\n", statuses[CacheStatusHit], ratio(statuses[CacheStatusHit]))
+	fmt.Fprintf(out, "  - Misses:           %d (%s)\n", statuses[CacheStatusMiss], ratio(statuses[CacheStatusMiss]))
+	fmt.Fprintf(out, "  - Other:            %d (%s)\n", other, ratio(other))
+	fmt.Fprintf(out, "  - Unknown:          %d (%s)\n", statuses[""], ratio(statuses[""]))
+}
+
+func (s cacheSummary) print(out io.Writer) {
+	printCacheStatuses(out, "Cache status:", s.statuses)
+	if s.warmed == 0 {
+		return
+	}
+
+	printCacheStatuses(out, "Warm cache status:", s.warmStatuses)
+	cold := newDurationStats(s.coldDuration)
+	warm := newDurationStats(s.warmDuration)
+	fmt.Fprintln(out, "Cold / warm duration:")
+	fmt.Fprintf(out, "  - Avg:              %s / %s\n", cold.avg.Round(time.Millisecond), warm.avg.Round(time.Millisecond))
+	for _, percentile := range []float64{50, 90, 99} {
+		fmt.Fprintf(out, "  - p%-16s %s / %s\n",
+			fmt.Sprintf("%g:", percentile),
+			cold.percentile(percentile).Round(time.Millisecond),
+			warm.percentile(percentile).Round(time.Millisecond),
+		)
+	}
+}
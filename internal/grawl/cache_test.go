@@ -0,0 +1,223 @@
+package grawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeCacheStatus(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "HIT", expected: CacheStatusHit},
+		{value: "hit", expected: CacheStatusHit},
+		{value: "Hit from cloudfront", expected: CacheStatusHit},
+		{value: "MISS", expected: CacheStatusMiss},
+		{value: "HIT, MISS", expected: CacheStatusMiss},
+		{value: "MISS, HIT", expected: CacheStatusHit},
+		{value: "expired", expected: "EXPIRED"},
+		{value: "BYPASS", expected: "BYPASS"},
+		{value: " ", expected: ""},
+		{value: "HIT, ", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if status := normalizeCacheStatus(test.value); status != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, status)
+			}
+		})
+	}
+}
+
+func TestCacheStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{name: "no headers", expected: ""},
+		{name: "cloudflare", headers: map[string]string{"CF-Cache-Status": "DYNAMIC"}, expected: "DYNAMIC"},
+		{name: "nginx", headers: map[string]string{"X-Cache-Status": "HIT"}, expected: CacheStatusHit},
+		{name: "x-cache", headers: map[string]string{"X-Cache": "Miss from cloudfront"}, expected: CacheStatusMiss},
+		{name: "proxy", headers: map[string]string{"X-Proxy-Cache": "MISS"}, expected: CacheStatusMiss},
+		{name: "cloudflare before x-cache", headers: map[string]string{"CF-Cache-Status": "HIT", "X-Cache": "MISS"}, expected: CacheStatusHit},
+		{name: "varnish hit", headers: map[string]string{"X-Varnish": "32770 32768"}, expected: CacheStatusHit},
+		{name: "varnish miss", headers: map[string]string{"X-Varnish": "32770"}, expected: CacheStatusMiss},
+		{name: "age", headers: map[string]string{"Age": "120"}, expected: CacheStatusHit},
+		{name: "zero age", headers: map[string]string{"Age": "0"}, expected: CacheStatusMiss},
+		{name: "invalid age", headers: map[string]string{"Age": "old"}, expected: ""},
+		{name: "cache control only", headers: map[string]string{"Cache-Control": "max-age=60"}, expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := http.Header{}
+			for name, value := range test.headers {
+				headers.Set(name, value)
+			}
+			if status := cacheStatus(headers); status != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, status)
+			}
+		})
+	}
+}
+
+func TestRecordCacheHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Cache", "HIT")
+	headers.Set("Age", "120")
+	headers.Set("Server", "nginx")
+
+	tests := []struct {
+		name        string
+		headerNames []string
+		expected    string
+	}{
+		{name: "default headers", headerNames: DefaultCacheHeaders, expected: "X-Cache: HIT | Age: 120"},
+		{name: "lower case header names", headerNames: []string{"age", "server"}, expected: "Age: 120 | Server: nginx"},
+		{name: "missing headers", headerNames: []string{"X-Varnish"}, expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := &Result{cacheHeaders: []string{"X-Cache: MISS"}}
+			result.recordCacheHeaders(test.headerNames, headers)
+			if result.GetCacheHeaders() != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, result.GetCacheHeaders())
+			}
+			if result.cacheStatus != CacheStatusHit {
+				t.Errorf("expected the status %s, got %s", CacheStatusHit, result.cacheStatus)
+			}
+		})
+	}
+}
+
+func TestNewCacheSummary(t *testing.T) {
+	results := []*Result{
+		{cacheStatus: CacheStatusMiss, warmed: true, warmCacheStatus: CacheStatusHit},
+		{cacheStatus: CacheStatusMiss, warmed: true, warmCacheStatus: CacheStatusMiss},
+		{cacheStatus: CacheStatusHit},
+		{cacheStatus: ""},
+	}
+
+	summary := newCacheSummary(results)
+	if summary.warmed != 2 || len(summary.coldDuration) != 2 || len(summary.warmDuration) != 2 {
+		t.Errorf("expected 2 warmed results, got %+v", summary)
+	}
+
+	tests := []struct {
+		name     string
+		statuses map[string]int
+		expected map[string]int
+	}{
+		{name: "cache statuses", statuses: summary.statuses, expected: map[string]int{CacheStatusMiss: 2, CacheStatusHit: 1, "": 1}},
+		{name: "warm cache statuses", statuses: summary.warmStatuses, expected: map[string]int{CacheStatusMiss: 1, CacheStatusHit: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if fmt.Sprint(test.statuses) != fmt.Sprint(test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, test.statuses)
+			}
+		})
+	}
+}
+
+func TestGrawlWarmTwice(t *testing.T) {
+	// The cache misses the first request of each path and hits all further requests
+	var requestedMutex sync.Mutex
+	requested := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedMutex.Lock()
+		requested[r.URL.Path]++
+		count := requested[r.URL.Path]
+		requestedMutex.Unlock()
+
+		if count == 1 {
+			w.Header().Set("X-Cache", "MISS")
+		} else {
+			w.Header().Set("X-Cache", "HIT")
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/page">page</a><a href="/missing">missing</a></body></html>`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		warmTwice         bool
+		expectedWarmed    map[string]bool
+		expectedRequested map[string]int
+	}{
+		{
+			name:              "warm",
+			expectedWarmed:    map[string]bool{"/": false, "/page": false, "/missing": false},
+			expectedRequested: map[string]int{"/": 1, "/page": 1, "/missing": 1},
+		},
+		{
+			name:              "warm twice",
+			warmTwice:         true,
+			expectedWarmed:    map[string]bool{"/": true, "/page": true, "/missing": false},
+			expectedRequested: map[string]int{"/": 2, "/page": 2, "/missing": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requestedMutex.Lock()
+			clear(requested)
+			requestedMutex.Unlock()
+
+			grawler, err := NewGrawler(Flags{
+				FlagParallel:       1,
+				FlagRequestTimeout: 5,
+				FlagWarm:           true,
+				FlagWarmTwice:      test.warmTwice,
+				FlagCacheHeaders:   DefaultCacheHeaders,
+				FlagNoStatusBar:    true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			grawler.SetOutput(io.Discard)
+
+			if err = grawler.Crawl(context.Background(), server.URL+"/"); err != nil {
+				t.Fatal(err)
+			}
+
+			results := *grawler.runningRequests.GetValues()
+			if len(results) != len(test.expectedWarmed) {
+				t.Fatalf("expected %d results, got %d", len(test.expectedWarmed), len(results))
+			}
+			for _, result := range results {
+				path := result.url[len(server.URL):]
+				if result.cacheStatus != CacheStatusMiss || result.GetCacheHeaders() != "X-Cache: MISS" {
+					t.Errorf("expected the cache miss of the first request of %s, got %s", path, result.GetCacheHeaders())
+				}
+				if result.warmed != test.expectedWarmed[path] {
+					t.Errorf("expected %s to be warmed %v, got %v", path, test.expectedWarmed[path], result.warmed)
+				}
+				if result.warmed && (result.warmStatusCode != http.StatusOK || result.warmCacheStatus != CacheStatusHit) {
+					t.Errorf("expected a cache hit of the second request of %s, got %d %s", path, result.warmStatusCode, result.warmCacheStatus)
+				}
+			}
+
+			requestedMutex.Lock()
+			defer requestedMutex.Unlock()
+			if fmt.Sprint(requested) != fmt.Sprint(test.expectedRequested) {
+				t.Errorf("expected requests %v, got %v", test.expectedRequested, requested)
+			}
+		})
+	}
+}
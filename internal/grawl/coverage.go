@@ -0,0 +1,246 @@
+package grawl
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+)
+
+const (
+	CoverageBoth        = "both"
+	CoverageSitemapOnly = "sitemap-only"
+	CoverageCrawlOnly   = "crawl-only"
+)
+
+// coverageEntry is a page of the sitemap or of the link crawl with the status codes of both passes
+type coverageEntry struct {
+	bucket             string
+	url                string
+	sitemapStatusCode  int
+	crawlStatusCode    int
+	crawlFoundOnUrl    string
+	sitemapFoundOnUrl  string
+	hasSitemapResult   bool
+	hasCrawlResult     bool
+	crawlContentIsHtml bool
+}
+
+// Coverage grawls the sitemaps and the links of the website and compares the found pages. The pages are
+// sorted into three buckets: in both, only in the sitemap (orphans) and only found by links (missing in the sitemap).
+// The passes are grawled quietly, only the comparison is printed.
+func (g *Grawler) Coverage(rootUrl string) ExitCode {
+	if !g.promptMissingPassword() {
+		return ExitCodeConfigError
+	}
+
+	stopSignals := g.handleSignals()
+	defer stopSignals()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-g.stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	fmt.Fprintln(g.out(), "Grawling sitemaps of "+rootUrl)
+	sitemapGrawler, exitCode := g.coveragePass(ctx, rootUrl, true)
+	if sitemapGrawler == nil || exitCode == ExitCodeInterrupted {
+		return exitCode
+	}
+
+	fmt.Fprintln(g.out(), "Grawling links of "+rootUrl)
+	crawlGrawler, crawlExitCode := g.coveragePass(ctx, rootUrl, false)
+	if crawlGrawler == nil || crawlExitCode == ExitCodeInterrupted {
+		return crawlExitCode
+	}
+	exitCode = max(exitCode, crawlExitCode)
+
+	entries := newCoverageEntries(sitemapGrawler, crawlGrawler)
+	printCoverage(g.out(), entries)
+
+	if g.flags.FlagCoverageReport != "" {
+		fmt.Fprintf(g.out(), "Saving coverage report \"%s\".\n", g.flags.FlagCoverageReport)
+		if err := writeCoverageReport(g.flags.FlagCoverageReport, entries); err != nil {
+			fmt.Fprintln(g.out(), "Error writing coverage report:", err)
+		}
+	}
+
+	return exitCode
+}
+
+// coveragePass grawls the website once without printing the results and the summary. The result files and reports
+// are only written for the comparison.
+func (g *Grawler) coveragePass(ctx context.Context, rootUrl string, sitemap bool) (*Grawler, ExitCode) {
+	flags := g.flags
+	flags.FlagSitemap = sitemap
+	flags.FlagOutputFilename = ""
+	flags.FlagJunitReport = ""
+	flags.FlagHtmlReport = ""
+	flags.FlagWriteSitemap = ""
+	flags.FlagStateDir = ""
+	flags.FlagPauseOnError = false
+	flags.FlagTui = false
+
+	passGrawler, err := NewGrawler(flags)
+	if err != nil {
+		fmt.Fprintln(g.out(), "Invalid configuration:", err)
+		return nil, ExitCodeConfigError
+	}
+
+	startUrl := rootUrl
+	if sitemap {
+		startUrl = sitemapStartUrl(rootUrl)
+	}
+
+	passGrawler.SetOutput(io.Discard)
+	exitCode, err := passGrawler.crawl(ctx, startUrl, nil)
+	passGrawler.SetOutput(g.output)
+	if err != nil {
+		fmt.Fprintln(g.out(), err)
+		return nil, exitCode
+	}
+
+	if ctx.Err() != nil {
+		return passGrawler, ExitCodeInterrupted
+	}
+	return passGrawler, passGrawler.exitCode(startUrl)
+}
+
+func newCoverageEntries(sitemapGrawler *Grawler, crawlGrawler *Grawler) []*coverageEntry {
+	entries := map[string]*coverageEntry{}
+	entryOf := func(pageUrl string) *coverageEntry {
+		key := urlWithoutFragment(pageUrl)
+		entry, ok := entries[key]
+		if !ok {
+			entry = &coverageEntry{url: pageUrl}
+			entries[key] = entry
+		}
+		return entry
+	}
+
+	// Redirected pages are compared by the url they are redirected to, in the sitemap and in the links
+	for _, result := range *sitemapGrawler.runningRequests.GetValues() {
+		if _, ok := sitemapGrawler.sitemapPages.Load(result.initialRequestUrl); !ok {
+			continue
+		}
+		entry := entryOf(result.url)
+		entry.hasSitemapResult = true
+		entry.sitemapStatusCode = result.statusCode
+		entry.sitemapFoundOnUrl = result.foundOnUrl
+	}
+
+	for _, result := range *crawlGrawler.runningRequests.GetValues() {
+		entry := entryOf(result.url)
+		entry.hasCrawlResult = true
+		entry.crawlStatusCode = result.statusCode
+		entry.crawlFoundOnUrl = result.foundOnUrl
+		entry.crawlContentIsHtml = isHtmlContentType(result.contentType)
+	}
+
+	sorted := make([]*coverageEntry, 0, len(entries))
+	for _, entry := range entries {
+		switch {
+		case entry.hasSitemapResult && entry.hasCrawlResult:
+			entry.bucket = CoverageBoth
+		case entry.hasSitemapResult:
+			entry.bucket = CoverageSitemapOnly
+		case entry.crawlContentIsHtml && entry.crawlStatusCode >= 200 && entry.crawlStatusCode < 300:
+			// Only successfully grawled pages are missing in the sitemap, not broken links or images
+			entry.bucket = CoverageCrawlOnly
+		default:
+			continue
+		}
+		sorted = append(sorted, entry)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].bucket != sorted[j].bucket {
+			return sorted[i].bucket < sorted[j].bucket
+		}
+		return sorted[i].url < sorted[j].url
+	})
+	return sorted
+}
+
+// urlWithoutFragment removes the fragment of an url, it is never requested
+func urlWithoutFragment(pageUrl string) string {
+	parsedUrl, err := url.Parse(pageUrl)
+	if err != nil {
+		return pageUrl
+	}
+	parsedUrl.Fragment = ""
+	parsedUrl.RawFragment = ""
+	return parsedUrl.String()
+}
+
+func printCoverage(out io.Writer, entries []*coverageEntry) {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		counts[entry.bucket]++
+	}
+
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Coverage")
+	fmt.Fprintf(out, "  - In sitemap and found by links: %d\n", counts[CoverageBoth])
+	fmt.Fprintf(out, "  - Only in sitemap (orphans):     %d\n", counts[CoverageSitemapOnly])
+	for _, entry := range entries {
+		if entry.bucket == CoverageSitemapOnly {
+			fmt.Fprintf(out, "      %d %s\n", entry.sitemapStatusCode, entry.url)
+		}
+	}
+	fmt.Fprintf(out, "  - Only found by links:           %d\n", counts[CoverageCrawlOnly])
+	for _, entry := range entries {
+		if entry.bucket == CoverageCrawlOnly {
+			fmt.Fprintf(out, "      %d %s (found on %s)\n", entry.crawlStatusCode, entry.url, entry.crawlFoundOnUrl)
+		}
+	}
+}
+
+// writeCoverageReport writes the buckets as csv file
+func writeCoverageReport(filePath string, entries []*coverageEntry) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	rows := [][]string{{
+		"Bucket",
+		"URL",
+		"Sitemap status code",
+		"Crawl status code",
+		"Sitemap URL",
+		"Found on URL",
+	}}
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.bucket,
+			entry.url,
+			formatCoverageStatusCode(entry.hasSitemapResult, entry.sitemapStatusCode),
+			formatCoverageStatusCode(entry.hasCrawlResult, entry.crawlStatusCode),
+			entry.sitemapFoundOnUrl,
+			entry.crawlFoundOnUrl,
+		})
+	}
+
+	return writer.WriteAll(rows)
+}
+
+func formatCoverageStatusCode(hasResult bool, statusCode int) string {
+	if !hasResult {
+		return ""
+	}
+	return strconv.Itoa(statusCode)
+}
@@ -0,0 +1,106 @@
+package grawl
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newCoverageTestServer serves a website whose sitemap lists the page and the orphan /orphan. The home page links
+// /page and /hidden, which is missing in the sitemap. /moved redirects to /page.
+func newCoverageTestServer(t *testing.T, robotsTxt bool, sitemapPage string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	writeHtml := func(w http.ResponseWriter, body string) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>"+body+"</body></html>")
+	}
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if !robotsTxt {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "User-agent: *\nSitemap: %s/sitemap.xml\n", server.URL)
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><urlset %s><url><loc>%s%s</loc></url><url><loc>%s/orphan</loc></url></urlset>`,
+			sitemapXmlns, server.URL, sitemapPage, server.URL)
+	})
+	mux.Handle("/moved", http.RedirectHandler("/page", http.StatusMovedPermanently))
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeHtml(w, `<a href="/page">page</a><a href="/hidden">hidden</a>`)
+	})
+	for _, page := range []string{"/page", "/orphan", "/hidden"} {
+		mux.HandleFunc(page, func(w http.ResponseWriter, r *http.Request) {
+			writeHtml(w, "")
+		})
+	}
+
+	return server
+}
+
+func TestCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		robotsTxt   bool
+		sitemapPage string
+	}{
+		{
+			name:        "sitemap of the robots.txt",
+			robotsTxt:   true,
+			sitemapPage: "/page",
+		},
+		{
+			name:        "missing robots.txt",
+			robotsTxt:   false,
+			sitemapPage: "/page",
+		},
+		{
+			name:        "redirected sitemap page",
+			robotsTxt:   true,
+			sitemapPage: "/moved",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newCoverageTestServer(t, test.robotsTxt, test.sitemapPage)
+
+			grawler, err := NewGrawler(Flags{
+				FlagParallel:       2,
+				FlagRequestTimeout: 5,
+				FlagNoStatusBar:    true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			output := &bytes.Buffer{}
+			grawler.SetOutput(output)
+
+			if exitCode := grawler.Coverage(server.URL); exitCode != ExitCodeOk {
+				t.Fatalf("expected exit code %d, got %d: %s", ExitCodeOk, exitCode, output)
+			}
+
+			for _, expected := range []string{
+				"In sitemap and found by links: 1",
+				"Only in sitemap (orphans):     1\n      200 " + server.URL + "/orphan\n",
+				"Only found by links:           2\n",
+				"200 " + server.URL + "/hidden (found on " + server.URL + "/)",
+			} {
+				if !strings.Contains(output.String(), expected) {
+					t.Errorf("expected %q in the output, got %s", expected, output)
+				}
+			}
+		})
+	}
+}
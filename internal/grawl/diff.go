@@ -0,0 +1,300 @@
+package grawl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/fatih/color"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	DiffNewError    = "new-error"
+	DiffFixed       = "fixed"
+	DiffDisappeared = "disappeared"
+	DiffNew         = "new"
+	DiffStatus      = "status-changed"
+	DiffSlower      = "slower"
+)
+
+// diffCategories are the categories in the order they are printed. Regressions lead to a non-zero exit code.
+var diffCategories = []struct {
+	name       string
+	title      string
+	regression bool
+}{
+	{DiffNewError, "New errors", true},
+	{DiffDisappeared, "Disappeared urls", true},
+	{DiffSlower, "Slower responses", true},
+	{DiffStatus, "Changed status codes", false},
+	{DiffNew, "New urls", false},
+	{DiffFixed, "Fixed errors", false},
+}
+
+type DiffFlags struct {
+	FlagOutputFilename     string
+	FlagResponseErrorCodes []string
+	FlagMaxSlowdown        float64
+	FlagMinSlowdownMs      int64
+}
+
+type diffEntry struct {
+	category      string
+	url           string
+	oldStatusCode int
+	newStatusCode int
+	oldDuration   time.Duration
+	newDuration   time.Duration
+	hasOld        bool
+	hasNew        bool
+}
+
+type diffEntryJson struct {
+	Category      string `json:"category"`
+	Url           string `json:"url"`
+	OldStatusCode int    `json:"old_status_code,omitempty"`
+	NewStatusCode int    `json:"new_status_code,omitempty"`
+	OldDurationMs int64  `json:"old_duration_ms,omitempty"`
+	NewDurationMs int64  `json:"new_duration_ms,omitempty"`
+}
+
+// Diff compares the output files of two grawlings and returns ExitCodeErrorsFound if there are regressions
+func Diff(oldPath string, newPath string, flags DiffFlags) ExitCode {
+	errorRanges, err := newResponseCodeRanges(flags.FlagResponseErrorCodes)
+	if err != nil {
+		fmt.Println("Invalid configuration:", err)
+		return ExitCodeConfigError
+	}
+
+	if flags.FlagOutputFilename != "" {
+		if _, err = getOutputFormat(flags.FlagOutputFilename, ""); err != nil {
+			fmt.Println("Invalid configuration:", err)
+			return ExitCodeConfigError
+		}
+	}
+
+	oldResults, err := readResults(oldPath, errorRanges)
+	if err != nil {
+		fmt.Printf("Could not read \"%s\": %v\n", oldPath, err)
+		return ExitCodeConfigError
+	}
+
+	newResults, err := readResults(newPath, errorRanges)
+	if err != nil {
+		fmt.Printf("Could not read \"%s\": %v\n", newPath, err)
+		return ExitCodeConfigError
+	}
+
+	entries := diffResults(oldResults, newResults, flags)
+	regressions := printDiff(entries, len(oldResults), len(newResults))
+
+	if flags.FlagOutputFilename != "" {
+		fmt.Printf("Saving diff \"%s\".\n", flags.FlagOutputFilename)
+		if err = writeDiff(flags.FlagOutputFilename, entries); err != nil {
+			fmt.Println("Error writing diff:", err)
+		}
+	}
+
+	if regressions > 0 {
+		return ExitCodeErrorsFound
+	}
+	return ExitCodeOk
+}
+
+// diffResults compares the results by their requested url, so redirected urls are compared with each other
+func diffResults(oldResults []*Result, newResults []*Result, flags DiffFlags) []*diffEntry {
+	oldByUrl := make(map[string]*Result, len(oldResults))
+	for _, result := range oldResults {
+		oldByUrl[result.initialRequestUrl] = result
+	}
+
+	newByUrl := make(map[string]*Result, len(newResults))
+	for _, result := range newResults {
+		newByUrl[result.initialRequestUrl] = result
+	}
+
+	entries := make([]*diffEntry, 0)
+	add := func(category string, url string, oldResult *Result, newResult *Result) {
+		entry := &diffEntry{category: category, url: url}
+		if oldResult != nil {
+			entry.oldStatusCode = oldResult.statusCode
+			entry.oldDuration = oldResult.GetDuration()
+			entry.hasOld = true
+		}
+		if newResult != nil {
+			entry.newStatusCode = newResult.statusCode
+			entry.newDuration = newResult.GetDuration()
+			entry.hasNew = true
+		}
+		entries = append(entries, entry)
+	}
+
+	for url, oldResult := range oldByUrl {
+		if _, ok := newByUrl[url]; !ok {
+			add(DiffDisappeared, url, oldResult, nil)
+		}
+	}
+
+	for url, newResult := range newByUrl {
+		oldResult, ok := oldByUrl[url]
+		if !ok {
+			add(DiffNew, url, nil, newResult)
+			if newResult.HasError() {
+				add(DiffNewError, url, nil, newResult)
+			}
+			continue
+		}
+
+		if newResult.HasError() && !oldResult.HasError() {
+			add(DiffNewError, url, oldResult, newResult)
+		} else if !newResult.HasError() && oldResult.HasError() {
+			add(DiffFixed, url, oldResult, newResult)
+		}
+
+		if newResult.statusCode != oldResult.statusCode {
+			add(DiffStatus, url, oldResult, newResult)
+		}
+
+		if isSlowdown(oldResult.GetDuration(), newResult.GetDuration(), flags) {
+			add(DiffSlower, url, oldResult, newResult)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].category != entries[j].category {
+			return entries[i].category < entries[j].category
+		}
+		return entries[i].url < entries[j].url
+	})
+	return entries
+}
+
+// isSlowdown checks if the response time grew more than the max slowdown in percent. Small differences
+// below the min slowdown are ignored, e.g. from 2ms to 5ms.
+func isSlowdown(oldDuration time.Duration, newDuration time.Duration, flags DiffFlags) bool {
+	if flags.FlagMaxSlowdown <= 0 || oldDuration <= 0 || newDuration <= oldDuration {
+		return false
+	}
+
+	if (newDuration - oldDuration).Milliseconds() < flags.FlagMinSlowdownMs {
+		return false
+	}
+
+	return float64(newDuration-oldDuration)*100/float64(oldDuration) > flags.FlagMaxSlowdown
+}
+
+// printDiff prints the entries by category and returns the number of regressions
+func printDiff(entries []*diffEntry, oldCount int, newCount int) int {
+	regressions := 0
+
+	for _, category := range diffCategories {
+		categoryEntries := make([]*diffEntry, 0)
+		for _, entry := range entries {
+			if entry.category == category.name {
+				categoryEntries = append(categoryEntries, entry)
+			}
+		}
+
+		fmt.Println("")
+		fmt.Printf("%s: %d\n", category.title, len(categoryEntries))
+		for _, entry := range categoryEntries {
+			row := "  " + entry.String()
+			if category.regression {
+				color.Red(row)
+			} else if category.name == DiffFixed || category.name == DiffNew {
+				color.Green(row)
+			} else {
+				color.Yellow(row)
+			}
+		}
+
+		if category.regression {
+			regressions += len(categoryEntries)
+		}
+	}
+
+	fmt.Println("")
+	fmt.Printf("Compared %d old with %d new results, %d regressions.\n", oldCount, newCount, regressions)
+	return regressions
+}
+
+func (e *diffEntry) String() string {
+	switch e.category {
+	case DiffNew:
+		return fmt.Sprintf("%d %s", e.newStatusCode, e.url)
+	case DiffDisappeared:
+		return fmt.Sprintf("%d %s", e.oldStatusCode, e.url)
+	case DiffSlower:
+		return fmt.Sprintf("%dms -> %dms %s", e.oldDuration.Milliseconds(), e.newDuration.Milliseconds(), e.url)
+	default:
+		if !e.hasOld {
+			return fmt.Sprintf("%d %s (new url)", e.newStatusCode, e.url)
+		}
+		return fmt.Sprintf("%d -> %d %s", e.oldStatusCode, e.newStatusCode, e.url)
+	}
+}
+
+// writeDiff writes the entries as csv or json lines file, depending on the file extension
+func writeDiff(filePath string, entries []*diffEntry) error {
+	format, err := getOutputFormat(filePath, "")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if format == OutputFormatJsonl {
+		encoder := json.NewEncoder(file)
+		for _, entry := range entries {
+			err = encoder.Encode(diffEntryJson{
+				Category:      entry.category,
+				Url:           entry.url,
+				OldStatusCode: entry.oldStatusCode,
+				NewStatusCode: entry.newStatusCode,
+				OldDurationMs: entry.oldDuration.Milliseconds(),
+				NewDurationMs: entry.newDuration.Milliseconds(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	rows := [][]string{{
+		"Category",
+		"URL",
+		"Old status code",
+		"New status code",
+		"Old duration (ms)",
+		"New duration (ms)",
+	}}
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.category,
+			entry.url,
+			formatDiffValue(entry.hasOld, int64(entry.oldStatusCode)),
+			formatDiffValue(entry.hasNew, int64(entry.newStatusCode)),
+			formatDiffValue(entry.hasOld, entry.oldDuration.Milliseconds()),
+			formatDiffValue(entry.hasNew, entry.newDuration.Milliseconds()),
+		})
+	}
+	return writer.WriteAll(rows)
+}
+
+func formatDiffValue(hasValue bool, value int64) string {
+	if !hasValue {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
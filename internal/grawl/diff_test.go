@@ -0,0 +1,100 @@
+package grawl
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// newDiffResult returns the result of a requested url with a status code and a duration in milliseconds
+func newDiffResult(t *testing.T, url string, statusCode int, durationMs int64) *Result {
+	t.Helper()
+
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewResult(0, url, "", errorCodeRanges)
+	result.statusCode = statusCode
+	result.responseAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result.requestAt = result.responseAt.Add(-time.Duration(durationMs) * time.Millisecond)
+	return result
+}
+
+func TestDiffResults(t *testing.T) {
+	flags := DiffFlags{FlagMaxSlowdown: 50, FlagMinSlowdownMs: 100}
+
+	tests := []struct {
+		name     string
+		old      []*Result
+		new      []*Result
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			old:      []*Result{newDiffResult(t, "/a", 200, 100)},
+			new:      []*Result{newDiffResult(t, "/a", 200, 120)},
+			expected: []string{},
+		},
+		{
+			name:     "new error",
+			old:      []*Result{newDiffResult(t, "/a", 200, 100)},
+			new:      []*Result{newDiffResult(t, "/a", 500, 100)},
+			expected: []string{"new-error /a", "status-changed /a"},
+		},
+		{
+			name:     "fixed error",
+			old:      []*Result{newDiffResult(t, "/a", 404, 100)},
+			new:      []*Result{newDiffResult(t, "/a", 200, 100)},
+			expected: []string{"fixed /a", "status-changed /a"},
+		},
+		{
+			name:     "disappeared and new urls",
+			old:      []*Result{newDiffResult(t, "/a", 200, 100)},
+			new:      []*Result{newDiffResult(t, "/b", 200, 100), newDiffResult(t, "/c", 404, 100)},
+			expected: []string{"disappeared /a", "new /b", "new /c", "new-error /c"},
+		},
+		{
+			name:     "slower by more than the percentage and the milliseconds",
+			old:      []*Result{newDiffResult(t, "/a", 200, 300)},
+			new:      []*Result{newDiffResult(t, "/a", 200, 500)},
+			expected: []string{"slower /a"},
+		},
+		{
+			name:     "slower by less than the milliseconds",
+			old:      []*Result{newDiffResult(t, "/a", 200, 10)},
+			new:      []*Result{newDiffResult(t, "/a", 200, 90)},
+			expected: []string{},
+		},
+		{
+			name:     "slower by less than the percentage",
+			old:      []*Result{newDiffResult(t, "/a", 200, 1000)},
+			new:      []*Result{newDiffResult(t, "/a", 200, 1400)},
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := make([]string, 0)
+			for _, entry := range diffResults(test.old, test.new, flags) {
+				entries = append(entries, entry.category+" "+entry.url)
+			}
+			if !slices.Equal(entries, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, entries)
+			}
+		})
+	}
+}
+
+func TestDiffResultsByRequestedUrl(t *testing.T) {
+	oldResult := newDiffResult(t, "/old", 200, 100)
+	newResult := newDiffResult(t, "/old", 200, 100)
+	newResult.url = "/new"
+	newResult.urlRedirectedFrom = "/old"
+
+	if entries := diffResults([]*Result{oldResult}, []*Result{newResult}, DiffFlags{}); len(entries) != 0 {
+		t.Errorf("expected no differences of a redirected url, got %d", len(entries))
+	}
+}
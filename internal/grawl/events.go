@@ -0,0 +1,44 @@
+package grawl
+
+// EventType is the kind of an Event of the grawling
+type EventType string
+
+const (
+	// EventRequest is emitted when a request is started
+	EventRequest EventType = "request"
+	// EventResponse is emitted when the response of a url has been processed without error
+	EventResponse EventType = "response"
+	// EventRedirect is emitted when a redirect is followed
+	EventRedirect EventType = "redirect"
+	// EventError is emitted when a url failed, e.g. with an error status code or without a response
+	EventError EventType = "error"
+	// EventFinished is emitted once after all requests have finished or the grawling has been stopped
+	EventFinished EventType = "finished"
+)
+
+// Event describes a step of the grawling. Url is the requested url, From the url it was found on or redirected from.
+// Result is set for responses and errors. Err is set for errors without a response and for the finished event
+// of a stopped grawling, it is the exhausted budget if the grawling has been truncated by a budget.
+type Event struct {
+	Type   EventType
+	Url    string
+	From   string
+	Result *Result
+	Err    error
+}
+
+// OnEvent registers a callback for the events of the grawling. The events are passed one after another
+// from the workers of the grawling, so a slow callback slows down the grawling.
+func (g *Grawler) OnEvent(callback func(Event)) {
+	g.eventCallback = callback
+}
+
+func (g *Grawler) emit(event Event) {
+	if g.eventCallback == nil {
+		return
+	}
+
+	g.eventMutex.Lock()
+	defer g.eventMutex.Unlock()
+	g.eventCallback(event)
+}
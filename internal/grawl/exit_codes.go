@@ -0,0 +1,17 @@
+package grawl
+
+// ExitCode is the exit code of the application after grawling
+type ExitCode int
+
+const (
+	// ExitCodeOk is returned if the grawling finished without exceeding the fail threshold
+	ExitCodeOk ExitCode = 0
+	// ExitCodeErrorsFound is returned if the errors exceed the fail threshold or the grawling stopped on an error
+	ExitCodeErrorsFound ExitCode = 1
+	// ExitCodeConfigError is returned if the configuration is invalid
+	ExitCodeConfigError ExitCode = 2
+	// ExitCodeStartUrlFailed is returned if the start url could not be requested or returned an error
+	ExitCodeStartUrlFailed ExitCode = 3
+	// ExitCodeInterrupted is returned if the grawling has been interrupted by a signal
+	ExitCodeInterrupted ExitCode = 130
+)
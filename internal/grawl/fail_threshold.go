@@ -0,0 +1,60 @@
+package grawl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type failThreshold struct {
+	value     float64
+	isPercent bool
+}
+
+// IsExceeded checks if the number of errors reaches the threshold. A threshold of 0 never fails.
+func (f *failThreshold) IsExceeded(errorCount int, totalCount int) bool {
+	if f.value <= 0 || errorCount <= 0 {
+		return false
+	}
+
+	if !f.isPercent {
+		return float64(errorCount) >= f.value
+	}
+
+	if totalCount <= 0 {
+		return false
+	}
+
+	return float64(errorCount)*100/float64(totalCount) >= f.value
+}
+
+func (f *failThreshold) String() string {
+	value := strconv.FormatFloat(f.value, 'f', -1, 64)
+	if f.isPercent {
+		return value + "%"
+	}
+	return value
+}
+
+func newFailThreshold(thresholdFlag string) (*failThreshold, error) {
+	thresholdFlag = strings.TrimSpace(thresholdFlag)
+	if thresholdFlag == "" {
+		return &failThreshold{value: 1}, nil
+	}
+
+	if strings.HasSuffix(thresholdFlag, "%") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(thresholdFlag, "%")), 64)
+		if err != nil || math.IsNaN(value) || value < 0 || value > 100 {
+			return nil, fmt.Errorf("could not parse fail threshold \"%s\": use a percentage between 0%% and 100%% like \"5%%\"", thresholdFlag)
+		}
+		return &failThreshold{value: value, isPercent: true}, nil
+	}
+
+	value, err := strconv.Atoi(thresholdFlag)
+	if err != nil || value < 0 {
+		return nil, fmt.Errorf("could not parse fail threshold \"%s\": use a number of errors like \"10\" or a percentage like \"5%%\"", thresholdFlag)
+	}
+
+	return &failThreshold{value: float64(value)}, nil
+}
@@ -0,0 +1,125 @@
+package grawl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewFailThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		expected string
+		invalid  bool
+	}{
+		{name: "default", flag: "", expected: "1"},
+		{name: "count", flag: " 10 ", expected: "10"},
+		{name: "percentage", flag: "5.5 %", expected: "5.5%"},
+		{name: "never fail", flag: "0", expected: "0"},
+		{name: "negative count", flag: "-1", invalid: true},
+		{name: "fractional count", flag: "1.5", invalid: true},
+		{name: "percentage above 100", flag: "101%", invalid: true},
+		{name: "not a number percentage", flag: "NaN%", invalid: true},
+		{name: "infinite percentage", flag: "Inf%", invalid: true},
+		{name: "negative infinite percentage", flag: "-Inf%", invalid: true},
+		{name: "not a number count", flag: "NaN", invalid: true},
+		{name: "no number", flag: "many", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			threshold, err := newFailThreshold(test.flag)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %v", threshold)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if threshold.String() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, threshold)
+			}
+		})
+	}
+}
+
+func TestFailThresholdIsExceeded(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		errorCount int
+		totalCount int
+		expected   bool
+	}{
+		{name: "count below", flag: "3", errorCount: 2, totalCount: 2, expected: false},
+		{name: "count reached", flag: "3", errorCount: 3, totalCount: 100, expected: true},
+		{name: "count without errors", flag: "1", errorCount: 0, totalCount: 10, expected: false},
+		{name: "percentage below", flag: "5%", errorCount: 4, totalCount: 100, expected: false},
+		{name: "percentage reached", flag: "5%", errorCount: 5, totalCount: 100, expected: true},
+		{name: "percentage of few requests", flag: "5%", errorCount: 1, totalCount: 10, expected: true},
+		{name: "percentage without requests", flag: "5%", errorCount: 1, totalCount: 0, expected: false},
+		{name: "never fail", flag: "0", errorCount: 10, totalCount: 10, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			threshold, err := newFailThreshold(test.flag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exceeded := threshold.IsExceeded(test.errorCount, test.totalCount); exceeded != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, exceeded)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/missing">missing</a><a href="/page">page</a></body></html>`)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		failThreshold string
+		expected      ExitCode
+	}{
+		{name: "errors below the threshold", path: "/", failThreshold: "2", expected: ExitCodeOk},
+		{name: "errors reach the threshold", path: "/", failThreshold: "1", expected: ExitCodeErrorsFound},
+		{name: "root without slash", path: "", failThreshold: "2", expected: ExitCodeOk},
+		{name: "start url failed", path: "/missing", failThreshold: "0", expected: ExitCodeStartUrlFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grawler, err := NewGrawler(Flags{
+				FlagParallel:       2,
+				FlagRequestTimeout: 5,
+				FlagFailThreshold:  test.failThreshold,
+				FlagNoStatusBar:    true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			grawler.SetOutput(io.Discard)
+
+			if exitCode := grawler.Grawl(server.URL + test.path); exitCode != test.expected {
+				t.Errorf("expected exit code %d, got %d", test.expected, exitCode)
+			}
+		})
+	}
+}
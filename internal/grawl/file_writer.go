@@ -2,6 +2,7 @@ package grawl
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,9 +11,11 @@ import (
 )
 
 type FileWriter struct {
-	sync.RWMutex
+	sync.Mutex
 	filePath        string
 	fileInitialized bool
+	file            *os.File
+	writer          *csv.Writer
 }
 
 func NewFileWriter(filePath string) *FileWriter {
@@ -22,44 +25,61 @@ func NewFileWriter(filePath string) *FileWriter {
 	}
 }
 
-func (f *FileWriter) InitFile() {
+func (f *FileWriter) InitFile(out io.Writer, resume bool) error {
 	if f.fileInitialized {
-		return
+		return nil
 	}
 
-	fmt.Printf("Saving file \"%s\".\n", f.filePath)
-
-	file, err := os.Create(f.filePath)
+	file, continued, err := openResultFile(out, f.filePath, resume)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	f.file = file
+	f.writer = csv.NewWriter(file)
+	f.writer.Comma = ';'
+
+	if !continued {
+		headers := f.getCsvHeader()
+		if err = f.write(headers); err != nil {
+			f.writer = nil
+			f.file = nil
+			return errors.Join(err, file.Close())
+		}
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	writer.Comma = ';'
-	defer writer.Flush()
-
-	headers := f.getCsvHeader()
-	f.write(headers, file)
 	f.fileInitialized = true
+	return nil
 }
 
-func (f *FileWriter) WriteResultLine(r *Result) {
-	f.RLock()
-	defer f.RUnlock()
+func (f *FileWriter) WriteResultLine(r *Result) error {
+	f.Lock()
+	defer f.Unlock()
 
 	if !f.fileInitialized {
 		panic("csv not initialized yet")
 	}
 
-	file, err := os.OpenFile(f.filePath, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-	if err != nil {
-		panic(err)
+	// The file has already been closed after the grawling stopped
+	if f.file == nil {
+		return nil
 	}
-	defer file.Close()
 
 	line := f.getCsvRow(r)
-	f.write(line, file)
+	return f.write(line)
+}
+
+// Close flushes and closes the file
+func (f *FileWriter) Close() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	f.writer.Flush()
+	err := errors.Join(f.writer.Error(), f.file.Close())
+	f.file = nil
+	return err
 }
 
 func (f *FileWriter) getCsvRow(r *Result) []string {
@@ -78,14 +98,29 @@ func (f *FileWriter) getCsvRow(r *Result) []string {
 		r.foundOnUrl,
 		r.contentType,
 		strconv.FormatInt(r.GetDuration().Milliseconds(), 10),
+		strconv.FormatInt(r.timing.dns.Milliseconds(), 10),
+		strconv.FormatInt(r.timing.connect.Milliseconds(), 10),
+		strconv.FormatInt(r.timing.tls.Milliseconds(), 10),
+		strconv.FormatInt(r.timing.ttfb.Milliseconds(), 10),
+		strconv.FormatInt(r.timing.download.Milliseconds(), 10),
 		strconv.Itoa(r.depth),
 		r.urlRedirectedFrom,
+		r.GetRedirectChain(),
+		r.GetLocation(),
 
 		r.urlHost,
 		r.urlPath,
 		r.urlParmeters,
 		r.urlFragment,
 
+		r.cacheStatus,
+		r.GetCacheHeaders(),
+		formatWarmStatusCode(r),
+		r.warmCacheStatus,
+		formatWarmDuration(r),
+
+		strconv.Itoa(r.GetAttempts()),
+		r.GetRetriedErrors(),
 		errorText,
 	}
 }
@@ -100,24 +135,52 @@ func (f *FileWriter) getCsvHeader() []string {
 		"Found on URL",
 		"Content type",
 		"Duration (ms)",
+		"DNS (ms)",
+		"Connect (ms)",
+		"TLS (ms)",
+		"TTFB (ms)",
+		"Download (ms)",
 		"Depth",
 		"Redirected from",
+		"Redirect chain",
+		"Location",
 
 		"Host",
 		"Path",
 		"Parameters",
 		"Fragment",
 
+		"Cache status",
+		"Cache headers",
+		"Warm status code",
+		"Warm cache status",
+		"Warm duration (ms)",
+
+		"Attempts",
+		"Retried errors",
 		"Info / error",
 	}
 }
 
-func (f *FileWriter) write(text []string, file io.Writer) {
-	writer := csv.NewWriter(file)
-	writer.Comma = ';'
-	defer writer.Flush()
+// write writes and flushes the line, so no line gets lost if the grawling is aborted
+func (f *FileWriter) write(text []string) error {
+	if err := f.writer.Write(text); err != nil {
+		return err
+	}
+	f.writer.Flush()
+	return f.writer.Error()
+}
+
+func formatWarmStatusCode(r *Result) string {
+	if !r.warmed {
+		return ""
+	}
+	return strconv.Itoa(r.warmStatusCode)
+}
 
-	if err := writer.Write(text); err != nil {
-		panic(err)
+func formatWarmDuration(r *Result) string {
+	if !r.warmed {
+		return ""
 	}
+	return strconv.FormatInt(r.warmDuration.Milliseconds(), 10)
 }
@@ -1,24 +1,60 @@
 package grawl
 
+import "time"
+
+// Flags configures a grawling. The json names are used in the state of a grawling, the password is never saved.
 type Flags struct {
-	FlagParallel             int
-	FlagDelay                int64
-	FlagRandomDelay          int64
-	FlagMaxDepth             int
-	FlagOutputFilename       string
-	FlagUsername             string
-	FlagPassword             string
-	FlagUserAgent            string
-	FlagSitemap              bool
-	FlagAllowedDomains       []string
-	FlagRespectRobotsTxt     bool
-	FlagRespectNofollow      bool
-	FlagPath                 string
-	FlagCheckAll             bool
-	FlagRequestTimeout       float32
-	FlagDisallowedURLFilters []string
-	FlagURLFilters           []string
-	FlagStopOnError          bool
-	FlagPauseOnError         bool
-	//FlagResponseErrorCodes   []string
+	FlagParallel             int           `json:"parallel"`
+	FlagDelay                int64         `json:"delay"`
+	FlagRandomDelay          int64         `json:"random_delay"`
+	FlagLimitRules           []LimitRule   `json:"limit_rules"`
+	FlagAdaptive             bool          `json:"adaptive"`
+	FlagAdaptiveMaxDelay     int64         `json:"adaptive_max_delay"`
+	FlagMaxDepth             int           `json:"max_depth"`
+	FlagMaxRedirects         int           `json:"max_redirects"`
+	FlagNoFollowRedirects    bool          `json:"no_follow_redirects"`
+	FlagVisitRedirectTargets bool          `json:"visit_redirect_targets"`
+	FlagOutputFilename       string        `json:"output_filename"`
+	FlagOutputFormat         string        `json:"output_format"`
+	FlagUsername             string        `json:"username"`
+	FlagPassword             string        `json:"-"`
+	FlagUserAgent            string        `json:"user_agent"`
+	FlagSitemap              bool          `json:"sitemap"`
+	FlagWarm                 bool          `json:"warm"`
+	FlagWarmTwice            bool          `json:"warm_twice"`
+	FlagCacheHeaders         []string      `json:"cache_headers"`
+	FlagCoverage             bool          `json:"coverage"`
+	FlagCoverageReport       string        `json:"coverage_report"`
+	FlagAllowedDomains       []string      `json:"allowed_domains"`
+	FlagRespectRobotsTxt     bool          `json:"respect_robots_txt"`
+	FlagRespectNofollow      bool          `json:"respect_nofollow"`
+	FlagPath                 string        `json:"path"`
+	FlagCheckAll             bool          `json:"check_all"`
+	FlagFollowElements       []string      `json:"follow_elements"`
+	FlagCheckElements        []string      `json:"check_elements"`
+	FlagRequestTimeout       float32       `json:"request_timeout"`
+	FlagRetries              int           `json:"retries"`
+	FlagRetryDelay           int64         `json:"retry_delay"`
+	FlagRetryOn              []string      `json:"retry_on"`
+	FlagDisallowedURLFilters []string      `json:"disallowed_url_filters"`
+	FlagURLFilters           []string      `json:"url_filters"`
+	FlagStopOnError          bool          `json:"stop_on_error"`
+	FlagPauseOnError         bool          `json:"pause_on_error"`
+	FlagResponseErrorCodes   []string      `json:"response_error_codes"`
+	FlagFailThreshold        string        `json:"fail_threshold"`
+	FlagJunitReport          string        `json:"junit_report"`
+	FlagJunitGroupBy         string        `json:"junit_group_by"`
+	FlagHtmlReport           string        `json:"html_report"`
+	FlagSlowest              int           `json:"slowest"`
+	FlagNoStatusBar          bool          `json:"no_status_bar"`
+	FlagTui                  bool          `json:"tui"`
+	FlagWriteSitemap         string        `json:"write_sitemap"`
+	FlagSitemapLastmod       bool          `json:"sitemap_lastmod"`
+	FlagSitemapBaseUrl       string        `json:"sitemap_base_url"`
+	FlagStateDir             string        `json:"state_dir"`
+	FlagStateInterval        int           `json:"state_interval"`
+	FlagShutdownTimeout      float32       `json:"shutdown_timeout"`
+	FlagMaxDuration          time.Duration `json:"max_duration"`
+	FlagMaxRequests          int           `json:"max_requests"`
+	FlagMaxErrors            int           `json:"max_errors"`
 }
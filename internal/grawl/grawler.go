@@ -1,13 +1,16 @@
 package grawl
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/gocolly/colly/v2"
 	"github.com/manifoldco/promptui"
+	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"regexp"
@@ -30,38 +33,210 @@ type Grawler struct {
 	requestCount        atomic.Uint32
 	responseCount       atomic.Uint32
 	errorCount          atomic.Uint32
-	totalDuration       time.Duration
 	runningRequests     *RunningRequests
-	fileWriter          *FileWriter
+	fileWriter          ResultWriter
+	writeErr            atomic.Pointer[error]
 	responseErrorRanges *responseCodeRanges
+	failThreshold       *failThreshold
+	limitRules          []LimitRule
+	throttle            *adaptiveThrottle
+	retryPolicy         *retryPolicy
+	retriedErrors       sync.Map
+	retryBackoffs       sync.Map
+	admittedThrottles   sync.Map
+	retryResponses      sync.Map
+	failedRequests      sync.Map
+	pause               pauseGate
+	errorController     *errorController
+	statusBar           *statusBar
+	tui                 *tui
 	collector           *colly.Collector
+	warmCollector       *colly.Collector
 	redirections        atomic.Uint32
 	visitMutex          sync.Mutex
+	stateMutex          sync.Mutex
+	restoredUrls        map[string]bool
+	sitemapPages        sync.Map
+	stopping            atomic.Bool
+	stopOnce            sync.Once
+	stopped             chan struct{}
+	stopExitCode        ExitCode
+	exhaustedBudget     *budgetError
+	budgetRequests      atomic.Int64
+	budgetErrors        atomic.Int64
+	output              io.Writer
+	eventCallback       func(Event)
+	eventMutex          sync.Mutex
 }
 
-func NewGrawler(flags Flags) *Grawler {
-	//errorCodeRanges, err := newResponseCodeRanges(flags.FlagResponseErrorCodes)
-	errorCodeRanges, err := newResponseCodeRanges([]string{})
+func NewGrawler(flags Flags) (*Grawler, error) {
+	errorCodeRanges, err := newResponseCodeRanges(flags.FlagResponseErrorCodes)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	return &Grawler{
+	threshold, err := newFailThreshold(flags.FlagFailThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	limitRules, err := EffectiveLimitRules(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkLinkExtractorNames(slices.Concat(flags.FlagFollowElements, flags.FlagCheckElements)); err != nil {
+		return nil, err
+	}
+
+	if flags.FlagSitemapBaseUrl != "" {
+		if err = checkSitemapBaseUrl(flags.FlagSitemapBaseUrl); err != nil {
+			return nil, err
+		}
+	}
+
+	if flags.FlagJunitReport != "" {
+		if err = checkJunitGroupBy(flags.FlagJunitGroupBy); err != nil {
+			return nil, err
+		}
+	}
+
+	var fileWriter ResultWriter
+	if flags.FlagOutputFilename != "" {
+		fileWriter, err = NewResultWriter(flags.FlagOutputFilename, flags.FlagOutputFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	policy, err := newRetryPolicy(flags.FlagRetries, flags.FlagRetryDelay, flags.FlagRetryOn)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Grawler{
 		flags:               flags,
-		totalDuration:       time.Duration(0),
 		runningRequests:     NewRunningRequests(),
 		responseErrorRanges: errorCodeRanges,
+		failThreshold:       threshold,
+		limitRules:          limitRules,
+		retryPolicy:         policy,
+		fileWriter:          fileWriter,
+		restoredUrls:        map[string]bool{},
+		stopped:             make(chan struct{}),
+	}
+
+	if flags.FlagAdaptive {
+		g.throttle = newAdaptiveThrottle(limitRules, time.Duration(flags.FlagAdaptiveMaxDelay)*time.Millisecond, g.out)
+	}
+
+	if flags.FlagPauseOnError {
+		g.errorController = newErrorController(g, os.Stdin)
+	}
+
+	return g, nil
+}
+
+// SetOutput sets the writer for the results and messages printed while grawling, e.g. io.Discard.
+// By default they are printed to stdout. The writes of the parallel requests are serialized, so the writer
+// does not need to be safe for concurrent use.
+func (g *Grawler) SetOutput(output io.Writer) {
+	if _, ok := output.(*syncWriter); !ok && output != nil {
+		output = &syncWriter{writer: output}
+	}
+	g.output = output
+}
+
+// syncWriter serializes the writes to a writer
+type syncWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.writer.Write(p)
+}
+
+// out returns the writer for the results and messages of the grawling. Stdout is resolved on each call,
+// because the tui captures it while running.
+func (g *Grawler) out() io.Writer {
+	if g.output != nil {
+		return g.output
 	}
+	return color.Output
 }
 
-func (g *Grawler) Grawl(grawlUrl string) {
+// Grawl grawls the given url and returns the exit code for the application
+func (g *Grawler) Grawl(grawlUrl string) ExitCode {
+	if g.flags.FlagSitemap {
+		grawlUrl = sitemapStartUrl(grawlUrl)
+	}
 
-	fmt.Println("Grawling " + grawlUrl)
+	fmt.Fprintln(g.out(), "Grawling "+grawlUrl)
+	return g.run(grawlUrl, nil)
+}
+
+// Resume continues an interrupted grawling from its saved state
+func (g *Grawler) Resume(state *State) ExitCode {
+	fmt.Fprintf(g.out(), "Resuming grawling of %s saved at %s\n", state.GrawlUrl, state.SavedAt)
+	return g.run(state.GrawlUrl, state)
+}
+
+func (g *Grawler) run(grawlUrl string, state *State) ExitCode {
+	if !g.promptMissingPassword() {
+		return ExitCodeConfigError
+	}
+
+	stopSignals := g.handleSignals()
+	defer stopSignals()
+
+	exitCode, err := g.crawl(context.Background(), grawlUrl, state)
+	if err != nil {
+		fmt.Fprintln(g.out(), err)
+		return exitCode
+	}
+
+	if g.flags.FlagStateDir != "" {
+		if err = g.saveState(grawlUrl); err != nil {
+			fmt.Fprintln(g.out(), "Error saving state:", err)
+		} else if g.isStopping() {
+			fmt.Fprintf(g.out(), "State saved to \"%s\". Resume with: grawler resume %s\n", g.flags.FlagStateDir, g.flags.FlagStateDir)
+		}
+	}
+
+	g.printSummary()
+	g.writeReports(grawlUrl)
+
+	if g.isStopping() && g.truncatedByBudget() == nil {
+		return g.stopExitCode
+	}
+
+	return g.exitCode(grawlUrl)
+}
+
+// Crawl grawls the given url until all requests are finished or the context is canceled. It neither handles
+// signals nor prompts for input and prints no summary, the progress is reported by the events of OnEvent.
+// Running requests are finished after the context has been canceled.
+func (g *Grawler) Crawl(ctx context.Context, grawlUrl string) error {
+	if g.flags.FlagSitemap {
+		grawlUrl = sitemapStartUrl(grawlUrl)
+	}
+
+	if _, err := g.crawl(ctx, grawlUrl, nil); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// crawl sets up the collector and grawls the url or continues the state. The returned exit code belongs to the
+// error of an invalid configuration or a failed start.
+func (g *Grawler) crawl(ctx context.Context, grawlUrl string, state *State) (ExitCode, error) {
 
 	parsedUrl, err := url.Parse(grawlUrl)
 	if err != nil {
-		fmt.Println("Error parsing the grawlUrl:", err)
-		return
+		return ExitCodeConfigError, fmt.Errorf("Error parsing the grawlUrl: %w", err)
 	}
 
 	c := colly.NewCollector()
@@ -92,21 +267,9 @@ func (g *Grawler) Grawl(grawlUrl string) {
 		c.UserAgent = g.flags.FlagUserAgent
 	}
 
-	limitingRule := &colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: g.flags.FlagParallel,
-	}
-
-	if g.flags.FlagRandomDelay > 0 {
-		limitingRule.RandomDelay = time.Duration(g.flags.FlagRandomDelay) * time.Millisecond
-	} else {
-		limitingRule.Delay = time.Duration(g.flags.FlagDelay) * time.Millisecond
-	}
-
-	err = c.Limit(limitingRule)
+	err = c.Limits(newCollyLimitRules(g.limitRules))
 	if err != nil {
-		fmt.Println("Error setting limits:", err)
-		return
+		return ExitCodeConfigError, fmt.Errorf("Error setting limits: %w", err)
 	}
 
 	c.IgnoreRobotsTxt = !g.flags.FlagRespectRobotsTxt
@@ -117,121 +280,331 @@ func (g *Grawler) Grawl(grawlUrl string) {
 	if len(g.flags.FlagURLFilters) > 0 {
 		c.URLFilters = append(c.URLFilters, regexp.MustCompile("^"+grawlUrl+"$"))
 		for _, filter := range g.flags.FlagURLFilters {
-			c.URLFilters = append(c.URLFilters, regexp.MustCompile(filter))
+			regex, err := regexp.Compile(filter)
+			if err != nil {
+				return ExitCodeConfigError, fmt.Errorf("Error parsing url filter \"%s\": %w", filter, err)
+			}
+			c.URLFilters = append(c.URLFilters, regex)
 		}
 	}
 
 	if len(g.flags.FlagDisallowedURLFilters) > 0 {
 		for _, filter := range g.flags.FlagDisallowedURLFilters {
-			c.DisallowedURLFilters = append(c.DisallowedURLFilters, regexp.MustCompile(filter))
-		}
-	}
-
-	if g.flags.FlagUsername != "" {
-		if g.flags.FlagPassword == "" {
-			g.flags.FlagPassword, err = g.promptPassword()
+			regex, err := regexp.Compile(filter)
 			if err != nil {
-				fmt.Println("error reading password:", err)
-				return
+				return ExitCodeConfigError, fmt.Errorf("Error parsing disallowed url filter \"%s\": %w", filter, err)
 			}
+			c.DisallowedURLFilters = append(c.DisallowedURLFilters, regex)
 		}
+	}
 
+	if g.flags.FlagUsername != "" {
 		var auth = base64.StdEncoding.EncodeToString([]byte(g.flags.FlagUsername + ":" + g.flags.FlagPassword))
 		g.headerAuth = fmt.Sprintf("Basic %s", auth)
 	}
 
-	c.SetRedirectHandler(g.onRedirect)
+	if g.flags.FlagNoFollowRedirects {
+		//
+		// Colly checks the filters of a redirect target before calling the redirect handler. Without following
+		// redirects every redirect response is reported, even if the target is filtered.
+		//
+		jar, _ := cookiejar.New(nil)
+		c.SetClient(&http.Client{
+			Transport:     g,
+			Jar:           jar,
+			Timeout:       time.Duration(g.flags.FlagRequestTimeout * float32(time.Second)),
+			CheckRedirect: g.onRedirect,
+		})
+	} else {
+		c.SetRedirectHandler(g.onRedirect)
+	}
 	c.OnRequest(g.onRequest)
 	c.OnResponse(g.onResponse)
 	c.OnError(g.onError)
 	c.OnResponseHeaders(g.onResponseHeaders)
 
 	if g.flags.FlagSitemap {
-		c.OnXML("//urlset/grawlUrl/loc", func(e *colly.XMLElement) {
-			g.visit(c, e.Request, e.Request.AbsoluteURL(e.Text), e.Request.URL.String())
-		})
-		c.OnXML("//sitemapindex/sitemap/loc", func(e *colly.XMLElement) {
-			g.visit(c, e.Request, e.Request.AbsoluteURL(e.Text), e.Request.URL.String())
-		})
-	} else {
-		c.OnHTML("a[href]", func(e *colly.HTMLElement) {
-			link := e.Attr("href")
-			if strings.HasPrefix(link, "mailto:") {
-				return
-			}
-			if strings.HasPrefix(link, "tel:") {
-				return
-			}
-			if g.flags.FlagRespectNofollow {
-				if strings.ToLower(e.Attr("rel")) == "nofollow" {
-					return
-				}
-			}
+		g.registerSitemapHandlers(c)
+	}
 
-			g.visit(c, e.Request, e.Request.AbsoluteURL(link), e.Request.URL.String())
-		})
+	g.registerLinkExtractors(c)
+
+	if g.flags.FlagWriteSitemap != "" {
+		g.registerSitemapPageInfo(c)
 	}
 
-	if g.flags.FlagCheckAll {
-		c.OnHTML("source[srcset]", func(e *colly.HTMLElement) {
-			imgSrc := e.Attr("srcset")
-			g.visit(c, e.Request, e.Request.AbsoluteURL(imgSrc), e.Request.URL.String())
-		})
+	if g.flags.FlagWarm && g.flags.FlagWarmTwice {
+		g.warmCollector = g.newWarmCollector(c)
+	}
 
-		c.OnHTML("img[src]", func(e *colly.HTMLElement) {
-			imgSrc := e.Attr("src")
-			g.visit(c, e.Request, e.Request.AbsoluteURL(imgSrc), e.Request.URL.String())
-		})
+	if g.fileWriter != nil {
+		if err = g.fileWriter.InitFile(g.out(), state != nil); err != nil {
+			return ExitCodeConfigError, fmt.Errorf("Error creating output file: %w", err)
+		}
+		defer g.closeFileWriter()
+	}
 
-		c.OnHTML("link[rel='stylesheet']", func(e *colly.HTMLElement) {
-			cssHref := e.Attr("href")
-			g.visit(c, e.Request, e.Request.AbsoluteURL(cssHref), e.Request.URL.String())
-		})
+	budgetCtx, cancelBudget := g.withDurationBudget(ctx)
+	defer cancelBudget()
 
-		c.OnHTML("script[src]", func(e *colly.HTMLElement) {
-			scriptSrc := e.Attr("src")
-			g.visit(c, e.Request, e.Request.AbsoluteURL(scriptSrc), e.Request.URL.String())
-		})
+	stopOnCancel := context.AfterFunc(budgetCtx, func() {
+		var budgetErr *budgetError
+		if errors.As(context.Cause(budgetCtx), &budgetErr) {
+			g.stopByBudget(budgetErr)
+			return
+		}
+		g.stop(ExitCodeInterrupted, "Grawling canceled. Waiting for running requests to finish.")
+	})
+	defer stopOnCancel()
+
+	if g.flags.FlagStateDir != "" {
+		stopCheckpoints := g.startStateCheckpoints(grawlUrl)
+		defer stopCheckpoints()
 	}
 
-	if g.flags.FlagOutputFilename != "" {
-		g.fileWriter = NewFileWriter(g.flags.FlagOutputFilename)
-		g.fileWriter.InitFile()
+	closeUi := func() {}
+	if g.flags.FlagTui {
+		closeUi = g.startTui()
+	}
+	// The status bar is started before the first request, so the callbacks of colly always see it
+	if g.tui == nil && !g.flags.FlagNoStatusBar {
+		closeUi = g.startStatusBar()
+	}
+	abortUi := func() {
+		if g.tui != nil {
+			g.abortTui()
+		} else {
+			closeUi()
+		}
 	}
 
-	err = c.Visit(grawlUrl)
-	if err != nil {
-		fmt.Printf("Could not visit grawlUrl: %v\n", err)
-		return
+	if state != nil {
+		if err = g.restoreState(c, state); err != nil {
+			abortUi()
+			return ExitCodeConfigError, fmt.Errorf("Error restoring state: %w", err)
+		}
+	} else {
+		g.runningRequests.AddQueuedUrl(grawlUrl, 1, "", false)
+		err = c.Visit(grawlUrl)
+		if err != nil {
+			g.runningRequests.RemoveQueuedUrl(grawlUrl)
+			abortUi()
+			return ExitCodeStartUrlFailed, fmt.Errorf("Could not visit grawlUrl: %w", err)
+		}
 	}
-	c.Wait()
+	g.wait()
+	closeUi()
+	writeErr := g.closeOutputFile()
+
+	finished := Event{Type: EventFinished, Url: grawlUrl}
+	if writeErr != nil {
+		finished.Err = writeErr
+	} else if budgetErr := g.truncatedByBudget(); budgetErr != nil {
+		finished.Err = budgetErr
+	} else if g.isStopping() {
+		finished.Err = errGrawlingStopped
+		if ctx.Err() != nil {
+			finished.Err = ctx.Err()
+		}
+	}
+	g.emit(finished)
 
-	g.printSummary()
+	if writeErr != nil {
+		return ExitCodeConfigError, writeErr
+	}
+	return ExitCodeOk, nil
+}
+
+// exitCode evaluates the results of the grawling
+func (g *Grawler) exitCode(grawlUrl string) ExitCode {
+	startUrl := grawlUrl
+	startResult, ok := g.runningRequests.LoadByUrl(requestedUrl(startUrl))
+
+	// The robots.txt of a sitemap grawling is optional, without it the default sitemap has to be grawled
+	if fallbackUrl := g.sitemapFallbackUrl(grawlUrl); fallbackUrl != "" && (!ok || startResult.HasError()) {
+		startUrl = fallbackUrl
+		startResult, ok = g.runningRequests.LoadByUrl(startUrl)
+	}
+	if !ok || startResult.HasError() {
+		fmt.Fprintf(g.out(), "The start url %s could not be grawled successfully.\n", startUrl)
+		return ExitCodeStartUrlFailed
+	}
+
+	results := *g.runningRequests.GetValues()
+	errorResults := 0
+	for _, result := range results {
+		// A missing robots.txt is no error of the website
+		if result.HasError() && (startUrl == grawlUrl || result.initialRequestUrl != grawlUrl) {
+			errorResults++
+		}
+	}
+
+	if g.failThreshold.IsExceeded(errorResults, len(results)) {
+		fmt.Fprintf(g.out(), "The errors (%d of %d) exceed the fail threshold of %s.\n", errorResults, len(results), g.failThreshold)
+		return ExitCodeErrorsFound
+	}
+
+	return ExitCodeOk
+}
+
+// requestedUrl returns the url as colly requests it, the root of a website always has the path "/"
+func requestedUrl(grawlUrl string) string {
+	parsedUrl, err := url.Parse(grawlUrl)
+	if err != nil || parsedUrl.Path != "" || parsedUrl.Opaque != "" {
+		return grawlUrl
+	}
+	parsedUrl.Path = "/"
+	return parsedUrl.String()
 }
 
 // RoundTrip implemnts the RoundTripper interface. Needed to measure roundtrip duration
 func (g *Grawler) RoundTrip(req *http.Request) (res *http.Response, err error) {
-	reqResult, ok := g.runningRequests.LoadByUrl(req.URL.String())
+	if g.isStopping() {
+		return nil, errGrawlingStopped
+	}
+
+	if isWarmRequest(req) {
+		return g.warmRoundTrip(req)
+	}
+
+	//
+	// Redirects are requested with a new request, the result belongs to the first request of the chain
+	//
+	firstRequest := initialRequest(req)
+	reqResult, ok := g.runningRequests.LoadByUrl(firstRequest.URL.String())
 	if !ok {
-		fmt.Printf("No running request found for %s\n", req.URL)
+		fmt.Fprintf(g.out(), "No running request found for %s\n", req.URL)
 		return http.DefaultTransport.RoundTrip(req)
 	}
-	reqResult.UpdateOnRoundTripStart(time.Now())
-	defer func() {
-		reqResult.UpdateOnRoundTripEnd(time.Now())
-	}()
-	return http.DefaultTransport.RoundTrip(req)
+
+	if firstRequest == req {
+		if err = g.checkSendRequest(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the first request of a redirect chain has a slot of the throttle, the hops adapt the throttle of their host
+	var throttle *hostThrottle
+	if firstRequest == req {
+		throttle = g.takeAdmittedThrottle(req.URL.String(), false)
+	}
+
+	if firstRequest == req {
+		reqResult.UpdateOnRoundTripStart(time.Now())
+		g.emit(Event{Type: EventRequest, Url: reqResult.initialRequestUrl, From: reqResult.foundOnUrl})
+	}
+
+	start := time.Now()
+	trace := &roundTripTrace{}
+	res, err = http.DefaultTransport.RoundTrip(trace.withTrace(req))
+	reqResult.UpdateOnRoundTripEnd(time.Now())
+	reqResult.AddTiming(trace.timing())
+
+	// The last response of a redirect chain overwrites the cache headers of the redirects
+	if err == nil && g.flags.FlagWarm {
+		reqResult.recordCacheHeaders(g.flags.FlagCacheHeaders, res.Header)
+	}
+
+	if err == nil {
+		res.Body = &timedBody{ReadCloser: res.Body, onDownloaded: func() {
+			reqResult.AddTiming(trace.downloadTiming())
+		}}
+	}
+
+	if throttle != nil {
+		throttle.update(res, err, time.Since(start))
+	} else if g.throttle != nil {
+		g.throttle.host(req.URL.Host).adapt(res, err, time.Since(start))
+	}
+
+	if err == nil && isRedirectStatusCode(res.StatusCode) && res.Header.Get("Location") != "" {
+		reqResult.AddRedirectHop(req.URL.String(), res.StatusCode)
+	}
+	return res, err
+}
+
+// admitRequest waits in OnRequest until the request may be sent: for the pause and for the throttle of the host.
+// The http client starts the timeout of the request later, so the waits do not count for it. It returns false if
+// the grawling has been stopped meanwhile.
+func (g *Grawler) admitRequest(requestUrl string, host string, warm bool) bool {
+	if !g.pause.wait(g.stopped) {
+		return false
+	}
+	return g.acquireThrottle(requestUrl, host, warm)
+}
+
+// checkSendRequest takes the request budget for a request which is about to be sent. The grawling may have been
+// paused while the request waited for the limits of colly, then errRequestPaused requests it again.
+func (g *Grawler) checkSendRequest() error {
+	if g.pause.isPaused() {
+		return errRequestPaused
+	}
+	if !g.takeRequestBudget() {
+		return errGrawlingStopped
+	}
+	return nil
+}
+
+// admittedRequest is the key of the throttle slot of a request. The second request of --warm-twice has its own slot.
+type admittedRequest struct {
+	url  string
+	warm bool
+}
+
+// acquireThrottle waits for a slot and the delay of the throttle of the url's host, e.g. for a Retry-After header.
+// It returns false if the grawling has been stopped meanwhile.
+func (g *Grawler) acquireThrottle(requestUrl string, host string, warm bool) bool {
+	if g.throttle == nil {
+		return true
+	}
+
+	throttle := g.throttle.host(host)
+	if !throttle.acquire(g.stopped) {
+		return false
+	}
+	g.admittedThrottles.Store(admittedRequest{url: requestUrl, warm: warm}, throttle)
+	return true
+}
+
+// takeAdmittedThrottle returns the throttle whose slot has been acquired for the url or nil
+func (g *Grawler) takeAdmittedThrottle(requestUrl string, warm bool) *hostThrottle {
+	throttle, ok := g.admittedThrottles.LoadAndDelete(admittedRequest{url: requestUrl, warm: warm})
+	if !ok {
+		return nil
+	}
+	return throttle.(*hostThrottle)
+}
+
+// releaseAdmittedThrottle frees the slot of a request which failed before it has been sent
+func (g *Grawler) releaseAdmittedThrottle(requestUrl string, warm bool) {
+	if throttle := g.takeAdmittedThrottle(requestUrl, warm); throttle != nil {
+		throttle.release()
+	}
 }
 
 func (g *Grawler) onRequest(r *colly.Request) {
+	// A retry waits for its backoff before colly takes a slot of the limits for it
+	if !g.waitRetryBackoff(r.URL.String()) || g.isStopping() {
+		r.Abort()
+		return
+	}
+
 	requestUrl := r.URL.String()
 
+	if !g.admitRequest(requestUrl, r.URL.Host, false) {
+		r.Abort()
+		return
+	}
+
 	if g.headerAuth != "" {
 		r.Headers.Set("Authorization", g.headerAuth)
 	}
 
 	foundOnUrl := g.runningRequests.GetFoundUrl(requestUrl)
 	requestResult := NewResult(r.ID, requestUrl, foundOnUrl, g.responseErrorRanges)
+	if retriedErrors, ok := g.retriedErrors.LoadAndDelete(requestUrl); ok {
+		requestResult.retriedErrors = retriedErrors.([]string)
+	}
 
 	g.runningRequests.Store(r.ID, requestResult, requestUrl)
 	g.requestCount.Add(1)
@@ -241,30 +614,88 @@ func (g *Grawler) onResponse(r *colly.Response) {
 	responseCount := g.responseCount.Add(1)
 	reqResult, ok := g.runningRequests.Load(r.Request.ID)
 	if !ok {
-		fmt.Printf("No start time found for %s\n", r.Request.URL)
+		fmt.Fprintf(g.out(), "No start time found for %s\n", r.Request.URL)
 	}
 
 	reqResult.UpdateOnResponse(r, responseCount, nil, g.requestCount.Load())
-	g.totalDuration += reqResult.GetDuration()
-
-	g.printResult(reqResult)
-	g.checkStopOnError(reqResult)
+	g.finishResult(reqResult, r.Request)
 }
 
 func (g *Grawler) onRedirect(req *http.Request, via []*http.Request) error {
+	if g.flags.FlagNoFollowRedirects || isWarmRequest(req) {
+		return http.ErrUseLastResponse
+	}
+
+	// Colly does not know the urls of a resumed grawling
+	if g.restoredUrls[req.URL.String()] {
+		return &colly.AlreadyVisitedError{Destination: req.URL}
+	}
+
 	runningReq, ok := g.runningRequests.LoadByUrl(via[0].URL.String())
 	g.redirections.Add(1)
-	if ok {
-		fmt.Printf("Redirecting to %s from %s. ID: %d\n", req.URL, via[0].URL, runningReq.id)
-	} else {
+	if !ok {
 		return fmt.Errorf("Could not find initial url of redirection to %s from %s\n", req.URL, via[0].URL)
 	}
+
+	for _, viaReq := range via {
+		if viaReq.URL.String() == req.URL.String() {
+			runningReq.StopRedirects(req.URL.String(), redirectStopLoop)
+			return errRedirectLoop
+		}
+	}
+
+	if len(via) > g.maxRedirects() {
+		runningReq.StopRedirects(req.URL.String(), redirectStopTooMany)
+		return fmt.Errorf("%w (max %d)", errTooManyRedirects, g.maxRedirects())
+	}
+
+	fmt.Fprintf(g.out(), "Redirecting to %s from %s. ID: %d\n", req.URL, via[0].URL, runningReq.id)
+	g.emit(Event{Type: EventRedirect, Url: req.URL.String(), From: via[len(via)-1].URL.String()})
 	return nil
 }
 
+func (g *Grawler) maxRedirects() int {
+	if g.flags.FlagMaxRedirects <= 0 {
+		return defaultMaxRedirects
+	}
+	return g.flags.FlagMaxRedirects
+}
+
 func (g *Grawler) onError(r *colly.Response, err error) {
+	if reqResult, ok := g.runningRequests.Load(r.Request.ID); ok {
+		g.releaseAdmittedThrottle(reqResult.initialRequestUrl, false)
+	}
+
 	// Normal error on aborted binary files like images. Result is printed in OnResponseHeaders
 	if err != nil && errors.Is(err, colly.ErrAbortedAfterHeaders) {
+		if response, ok := g.retryResponses.LoadAndDelete(r.Request.ID); ok && !g.retryRequest(response.(*colly.Response), nil) {
+			g.finishAbortedResponse(response.(*colly.Response))
+		}
+		return
+	}
+
+	//
+	// Requests that would have been sent during a pause wait for its end in OnRequest
+	//
+	if errors.Is(err, errRequestPaused) {
+		g.runningRequests.Delete(r.Request.ID)
+		g.requestCount.Add(^uint32(0))
+		if retryErr := r.Request.Retry(); retryErr != nil {
+			fmt.Fprintf(g.out(), "Could not request %s after the pause: %v\n", r.Request.URL, retryErr)
+		}
+		return
+	}
+
+	//
+	// Requests that have not been sent before the grawling stopped stay queued
+	//
+	if errors.Is(err, errGrawlingStopped) {
+		g.runningRequests.Delete(r.Request.ID)
+		g.requestCount.Add(^uint32(0))
+		return
+	}
+
+	if g.retryRequest(r, err) {
 		return
 	}
 
@@ -272,29 +703,71 @@ func (g *Grawler) onError(r *colly.Response, err error) {
 	responseCount := g.responseCount.Add(1)
 
 	//
-	// Remove request if this url is filtered by colly
+	// Remove request if this url is filtered by colly, e.g. a redirect to an already visited url
 	//
-	if r.StatusCode == 0 {
-		fmt.Println("Error", r.Request.URL, err)
+	if r.StatusCode == 0 && isSkippedByCollector(err) {
+		reqResult, ok := g.runningRequests.Load(r.Request.ID)
+
+		//
+		// A redirect to an already grawled url is kept, the redirect chain is part of the result
+		//
+		var alreadyVisitedErr *colly.AlreadyVisitedError
+		if ok && reqResult.HasRedirects() && errors.As(err, &alreadyVisitedErr) {
+			g.errorCount.Add(^uint32(0))
+			reqResult.StopRedirects(alreadyVisitedErr.Destination.String(), redirectStopVisited)
+			reqResult.UpdateOnResponse(r, responseCount, nil, g.requestCount.Load())
+			g.finishResult(reqResult, r.Request)
+			return
+		}
+
+		fmt.Fprintln(g.out(), "Skipped", r.Request.URL, err)
+		if ok {
+			g.runningRequests.RemoveQueuedUrl(reqResult.initialRequestUrl)
+		}
 		g.runningRequests.Delete(r.Request.ID)
 		return
 	}
 
+	//
+	// Colly reports every status code >= 203 as error. Whether the status code is an error
+	// is decided by the response error code ranges.
+	//
+	if isHttpStatusError(r, err) {
+		err = nil
+	}
+
 	reqResult, ok := g.runningRequests.Load(r.Request.ID)
 	if ok {
-		reqResult.UpdateOnResponse(r, responseCount, &err, g.requestCount.Load())
-		g.totalDuration += reqResult.GetDuration()
-		g.printResult(reqResult)
-		g.checkStopOnError(reqResult)
+		var resErr *error
+		if err != nil {
+			resErr = &err
+		}
+		reqResult.UpdateOnResponse(r, responseCount, resErr, g.requestCount.Load())
+		g.finishResult(reqResult, r.Request)
+		g.visitRedirectTarget(reqResult, r.Request)
 	} else {
-		fmt.Println("Request data not found", r.Request.URL)
+		fmt.Fprintln(g.out(), "Request data not found", r.Request.URL)
 	}
 }
 
-func (g *Grawler) visit(c *colly.Collector, r *colly.Request, url string, foundOnUrl string) {
+// visit queues a found url. The links of the url's page are only grawled if followLinks is true.
+func (g *Grawler) visit(c *colly.Collector, r *colly.Request, url string, foundOnUrl string, followLinks bool) {
 	g.visitMutex.Lock()
 
 	url = strings.Trim(url, " ")
+
+	//
+	// Keep found urls queued for a resumed grawling but do not request them anymore
+	//
+	if g.isStopping() {
+		if !g.runningRequests.HasFoundUrl(url) {
+			g.runningRequests.AddFoundUrl(url, foundOnUrl)
+			g.runningRequests.AddQueuedUrl(url, r.Depth+1, foundOnUrl, !followLinks)
+		}
+		g.visitMutex.Unlock()
+		return
+	}
+
 	visited, err := c.HasVisited(url)
 	if visited {
 		//fmt.Println("Visited", url)
@@ -302,29 +775,78 @@ func (g *Grawler) visit(c *colly.Collector, r *colly.Request, url string, foundO
 		return
 	}
 	if err != nil {
-		fmt.Println("Could not check if url has been visited: ", url)
+		fmt.Fprintln(g.out(), "Could not check if url has been visited: ", url)
 	}
 
 	hasFoundUrl := g.runningRequests.HasFoundUrl(url)
 	if hasFoundUrl {
+		// A page which is linked by a followed element type is followed, even if it has been found before
+		if followLinks {
+			g.runningRequests.RemoveCheckOnlyUrl(url)
+		}
 		g.visitMutex.Unlock()
 		return
 	}
 
 	g.runningRequests.AddFoundUrl(url, foundOnUrl)
+	g.runningRequests.AddQueuedUrl(url, r.Depth+1, foundOnUrl, !followLinks)
 	//fmt.Println("Visit:", url)
-	_ = r.Visit(url)
+	err = r.Visit(url)
+	if err != nil {
+		g.runningRequests.RemoveQueuedUrl(url)
+	}
 	g.visitMutex.Unlock()
 }
 
+// visitRedirectTarget queues the location of a redirect response which has not been followed, see --visit-redirect-targets
+func (g *Grawler) visitRedirectTarget(result *Result, r *colly.Request) {
+	if !g.flags.FlagVisitRedirectTargets || result.GetLocation() == "" {
+		return
+	}
+	g.visit(g.collector, r, result.GetLocation(), result.url, true)
+}
+
+// finishResult is called when the response of a request has been processed. With --warm-twice the result is
+// completed after the second request of the url.
+func (g *Grawler) finishResult(result *Result, request *colly.Request) {
+	if g.warmCollector != nil && g.warmAgain(result, request) {
+		return
+	}
+	g.completeResult(result, request)
+}
+
+// completeResult reports a finished result. The depth of a failed url is kept, so the url can be retried on demand.
+func (g *Grawler) completeResult(result *Result, request *colly.Request) {
+	if result.HasError() {
+		g.failedRequests.Store(result.initialRequestUrl, request.Depth)
+	}
+	g.runningRequests.Done(result)
+	g.printResult(result)
+
+	if result.HasError() {
+		g.emit(Event{Type: EventError, Url: result.url, From: result.foundOnUrl, Result: result, Err: result.error})
+	} else {
+		g.emit(Event{Type: EventResponse, Url: result.url, From: result.foundOnUrl, Result: result})
+	}
+	g.countErrorBudget(result)
+	g.checkStopOnError(result)
+}
+
 func (g *Grawler) printSummary() {
-	durationMin := time.Hour
-	durationMax := time.Duration(0)
 	returnCodes := map[int]int{}
 	returnErrors := 0
-	for _, result := range *g.runningRequests.GetValues() {
-		durationMin = min(durationMin, result.GetDuration())
-		durationMax = max(durationMax, result.GetDuration())
+	errorResults := 0
+	retries := 0
+	recoveredByRetry := 0
+	results := *g.runningRequests.GetValues()
+	for _, result := range results {
+		retries += len(result.retriedErrors)
+		if len(result.retriedErrors) > 0 && !result.HasError() {
+			recoveredByRetry++
+		}
+		if result.HasError() {
+			errorResults++
+		}
 		if result.statusCode > 0 {
 			returnCodes[result.statusCode]++
 		} else {
@@ -338,49 +860,153 @@ func (g *Grawler) printSummary() {
 	}
 	sort.Ints(returnCodeKeys)
 
-	fmt.Println("")
-	fmt.Println("Grawling finished at:", time.Now().Format(DateFormat))
-	fmt.Println("Duration:            ", g.totalDuration.Round(time.Millisecond))
-	fmt.Println("  - Min:             ", durationMin.Round(time.Millisecond))
-	fmt.Println("  - Max:             ", durationMax.Round(time.Millisecond))
-	fmt.Println("  - Avg:             ", time.Duration(int64(g.totalDuration)/int64(g.requestCount.Load())).Round(time.Millisecond))
-	fmt.Println("Requests:            ", g.requestCount.Load())
+	fmt.Fprintln(g.out(), "")
+	if g.isStopping() {
+		fmt.Fprintf(g.out(), "Grawling stopped at:  %s (%d urls not grawled)\n", time.Now().Format(DateFormat), g.runningRequests.QueuedCount())
+		if budgetErr := g.truncatedByBudget(); budgetErr != nil {
+			fmt.Fprintf(g.out(), "Truncated by budget:  %s\n", budgetErr)
+		}
+	} else {
+		fmt.Fprintln(g.out(), "Grawling finished at:", time.Now().Format(DateFormat))
+	}
+	durations := newDurationStats(sortedDurations(results, (*Result).GetDuration))
+	printDurationStats(g.out(), durations)
+	printHistogram(g.out(), durations)
+	fmt.Fprintln(g.out(), "Timings:              p50 / p90 / p99")
+	for _, phase := range timingPhases {
+		phaseResults := results
+		if phase.connection {
+			// Reused connections have no connection phases, their zeros would pull down the percentiles
+			phaseResults = slices.DeleteFunc(slices.Clone(results), func(result *Result) bool {
+				return !result.timing.newConnection
+			})
+		}
+		durations := sortedDurations(phaseResults, func(result *Result) time.Duration {
+			return phase.duration(result.timing)
+		})
+		fmt.Fprintf(g.out(), "  - %-17s %s / %s / %s\n",
+			phase.name+":",
+			durationPercentile(durations, 50).Round(time.Millisecond),
+			durationPercentile(durations, 90).Round(time.Millisecond),
+			durationPercentile(durations, 99).Round(time.Millisecond),
+		)
+	}
+	fmt.Fprintln(g.out(), "Requests:            ", g.requestCount.Load())
 	for _, code := range returnCodeKeys {
-		fmt.Printf("  - Status code %d:  %d\n", code, returnCodes[code])
+		fmt.Fprintf(g.out(), "  - Status code %d:  %d\n", code, returnCodes[code])
+	}
+	fmt.Fprintf(g.out(), "  - Other errors:     %d\n", returnErrors)
+	if g.retryPolicy.retries > 0 {
+		fmt.Fprintf(g.out(), "  - Retries:          %d (%d urls recovered)\n", retries, recoveredByRetry)
+	}
+	fmt.Fprintf(g.out(), "  - Redirections:     %d\n", g.redirections.Load())
+	redirects := newRedirectSummary(results)
+	fmt.Fprintf(g.out(), "Redirect chains:      %d\n", redirects.chains)
+	fmt.Fprintf(g.out(), "  - Multiple hops:    %d\n", redirects.multipleHops)
+	fmt.Fprintf(g.out(), "  - Longest chain:    %d\n", redirects.longestChain)
+	fmt.Fprintf(g.out(), "  - Loops:            %d\n", redirects.loops)
+	fmt.Fprintf(g.out(), "  - Too many hops:    %d (max %d)\n", redirects.tooManyHops, g.maxRedirects())
+	fmt.Fprintf(g.out(), "  - To grawled urls:  %d\n", redirects.toGrawledUrls)
+	if g.flags.FlagNoFollowRedirects {
+		fmt.Fprintf(g.out(), "  - Not followed:     %d\n", redirects.notFollowed)
+	}
+	if g.throttle != nil {
+		g.throttle.printSummary(g.out())
+	}
+	printStatistics(g.out(), results, g.flags.FlagSlowest)
+	if g.flags.FlagWarm {
+		newCacheSummary(results).print(g.out())
+	}
+	fmt.Fprintf(g.out(), "Errors:               %d (response error codes: %s)\n", errorResults, g.responseErrorRanges)
+}
+
+func (g *Grawler) writeReports(grawlUrl string) {
+	if g.flags.FlagJunitReport != "" {
+		fmt.Fprintf(g.out(), "Saving junit report \"%s\".\n", g.flags.FlagJunitReport)
+		err := writeJunitReport(g.flags.FlagJunitReport, g.flags.FlagJunitGroupBy, grawlUrl, *g.runningRequests.GetValues())
+		if err != nil {
+			fmt.Fprintln(g.out(), "Error writing junit report:", err)
+		}
+	}
+
+	if g.flags.FlagHtmlReport != "" {
+		fmt.Fprintf(g.out(), "Saving html report \"%s\".\n", g.flags.FlagHtmlReport)
+		err := writeHtmlReport(g.flags.FlagHtmlReport, grawlUrl, *g.runningRequests.GetValues())
+		if err != nil {
+			fmt.Fprintln(g.out(), "Error writing html report:", err)
+		}
+	}
+
+	if g.flags.FlagWriteSitemap != "" {
+		pages, err := writeSitemap(g.flags.FlagWriteSitemap, sitemapBaseUrl(g.flags.FlagSitemapBaseUrl, grawlUrl), *g.runningRequests.GetValues(), g.flags.FlagSitemapLastmod)
+		if err != nil {
+			fmt.Fprintln(g.out(), "Error writing sitemap:", err)
+		} else {
+			fmt.Fprintf(g.out(), "Saved sitemap \"%s\" with %d pages.\n", g.flags.FlagWriteSitemap, pages)
+		}
 	}
-	fmt.Printf("  - Other errors:     %d\n", returnErrors)
-	fmt.Printf("  - Redirections:     %d\n", g.redirections.Load())
 }
 
 func (g *Grawler) printResult(result *Result) {
-	if result.IsRedirected() {
-		color.Yellow(result.GetPrintRow())
+	if g.tui != nil {
+		g.tui.add(result)
+	} else if result.IsRedirected() {
+		color.New(color.FgYellow).Fprintln(g.out(), result.GetPrintRow())
 	} else if result.HasError() {
-		color.Red(result.GetPrintRow())
+		color.New(color.FgRed).Fprintln(g.out(), result.GetPrintRow())
 	} else {
-		color.Green(result.GetPrintRow())
+		color.New(color.FgGreen).Fprintln(g.out(), result.GetPrintRow())
 	}
 
 	if g.fileWriter != nil {
-		g.fileWriter.WriteResultLine(result)
+		if err := g.fileWriter.WriteResultLine(result); err != nil {
+			g.failWriting(err)
+		}
 	}
 
 	g.updateStatusBar(result)
 }
 
 func (g *Grawler) updateStatusBar(result *Result) {
-	//fmt.Printf("queue %d", g.collector.)
-	//line := "cool: " + result.GetPrintRow()
-	//
-	//printFixedBottomLine(line)
+	if g.statusBar != nil {
+		g.statusBar.add(result)
+	}
+}
+
+// forgetStatusBar removes a result from the status bar, e.g. when a failed url is retried
+func (g *Grawler) forgetStatusBar(result *Result) {
+	if g.statusBar != nil {
+		g.statusBar.forget(result)
+	}
 }
 
-func printFixedBottomLine(line string) {
-	// ANSI Code um den Cursor auf die letzte Zeile zu setzen
-	fmt.Printf("\033[s")              // Speichere die aktuelle Cursor-Position
-	fmt.Printf("\033[H\033[2J")       // Lösche den Bildschirm
-	fmt.Printf("\033[999;1H%s", line) // Setze Cursor an die letzte Zeile und drucke die Zeile
-	fmt.Printf("\033[u")              // Stelle die gespeicherte Cursor-Position wieder her
+// hideStatusBar removes the status bar while the user is asked for input
+func (g *Grawler) hideStatusBar() {
+	if g.statusBar != nil {
+		g.statusBar.hide()
+	}
+}
+
+func (g *Grawler) showStatusBar() {
+	if g.statusBar != nil {
+		g.statusBar.show()
+	}
+}
+
+// promptMissingPassword asks for the password of the basic authentication if only the username is set.
+// It returns false if the password could not be read.
+func (g *Grawler) promptMissingPassword() bool {
+	if g.flags.FlagUsername == "" || g.flags.FlagPassword != "" {
+		return true
+	}
+
+	password, err := g.promptPassword()
+	if err != nil {
+		fmt.Fprintln(g.out(), "error reading password:", err)
+		return false
+	}
+	g.flags.FlagPassword = password
+	return true
 }
 
 func (g *Grawler) promptPassword() (string, error) {
@@ -397,7 +1023,7 @@ func (g *Grawler) promptPassword() (string, error) {
 	result, err := prompt.Run()
 
 	if err != nil {
-		fmt.Printf("Prompt failed %v\n", err)
+		fmt.Fprintf(g.out(), "Prompt failed %v\n", err)
 		return "", err
 	}
 
@@ -405,75 +1031,60 @@ func (g *Grawler) promptPassword() (string, error) {
 }
 
 func (g *Grawler) checkStopOnError(result *Result) {
-	if !result.HasError() {
+	if !result.HasError() || g.isStopping() {
 		return
 	}
 
 	if g.flags.FlagStopOnError {
-		fmt.Println("Stop grawling after error.")
 		if result.error != nil {
-			fmt.Printf("Grawling error: %s\n", result.error.Error())
+			fmt.Fprintf(g.out(), "Grawling error: %s\n", result.error.Error())
 		}
-		os.Exit(1)
+		g.stop(ExitCodeErrorsFound, "Stop grawling after error.")
+		return
 	}
 
-	if g.flags.FlagPauseOnError {
-		fmt.Println("Pause grawling after error.")
-		prompt := g.promptResume()
-		switch prompt {
-		case "a":
-			fmt.Println("Grawling aborted.")
-			os.Exit(1)
-		case "s":
-			fmt.Println("Url skipped.")
-			return
-		case "r":
-			fmt.Println("Retry url...")
-			allowRevisit := g.collector.AllowURLRevisit
-			g.collector.AllowURLRevisit = true
-			_ = g.collector.Visit(result.url)
-			g.collector.AllowURLRevisit = allowRevisit
-		}
+	if g.flags.FlagPauseOnError && g.tui != nil {
+		g.tui.pauseOnError(result)
+		return
 	}
-}
 
-func (g *Grawler) promptResume() interface{} {
-	str := "Please choose: [a]bort the grawling or [r]etry the url or [s]kip the url."
-	for {
-		fmt.Println(str)
-		var input string
-		_, err := fmt.Scanln(&input)
-		if err != nil {
-			return nil
-		}
-		input = strings.ToLower(strings.TrimSpace(input))
-
-		switch input {
-		case "a", "s", "r":
-			return input
-		default:
-			//fmt.Println(str)
-		}
+	if g.errorController != nil {
+		g.errorController.add(result)
 	}
 }
 
 func (g *Grawler) onResponseHeaders(r *colly.Response) {
-	if isHtmlResponse(r) || isXmlResponse(r) {
+	//
+	// Responses which are retried are not downloaded, the retry is started in onError
+	//
+	if g.canRetry(r, nil) {
+		g.retryResponses.Store(r.Request.ID, r)
+		r.Request.Abort()
+		return
+	}
+
+	reqResult, ok := g.runningRequests.Load(r.Request.ID)
+	checkOnly := ok && g.runningRequests.IsCheckOnlyUrl(reqResult.initialRequestUrl)
+
+	if (isHtmlResponse(r) || isXmlResponse(r) || (g.flags.FlagSitemap && isRobotsTxtResponse(r))) && !checkOnly {
 		return
 	}
 
 	//
-	// Abort downloading all non-xml and non-html contents
+	// Abort downloading all non-xml and non-html contents and pages whose links are not followed
 	//
 	r.Request.Abort()
+	g.finishAbortedResponse(r)
+}
+
+// finishAbortedResponse finishes the result of a response whose body has not been downloaded
+func (g *Grawler) finishAbortedResponse(r *colly.Response) {
 	reqResult, ok := g.runningRequests.Load(r.Request.ID)
 	if ok {
 		responseCount := g.responseCount.Add(1)
 		reqResult.UpdateOnResponse(r, responseCount, nil, g.requestCount.Load())
-		g.totalDuration += reqResult.GetDuration()
-		g.printResult(reqResult)
-		g.checkStopOnError(reqResult)
+		g.finishResult(reqResult, r.Request)
 	} else {
-		fmt.Println("Request data not found", r.Request.URL)
+		fmt.Fprintln(g.out(), "Request data not found", r.Request.URL)
 	}
 }
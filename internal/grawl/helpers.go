@@ -1,6 +1,7 @@
 package grawl
 
 import (
+	"errors"
 	"github.com/gocolly/colly/v2"
 	"net/http"
 	"strings"
@@ -146,7 +147,26 @@ func isXmlResponse(resp *colly.Response) bool {
 	return !isHtmlContentType && (isXMLFile || isXmlContentType)
 }
 
+// isHttpStatusError checks if the error is the one colly creates for every status code >= 203
+func isHttpStatusError(resp *colly.Response, err error) bool {
+	return err != nil && resp.StatusCode >= 203 && err.Error() == http.StatusText(resp.StatusCode)
+}
+
+// isSkippedByCollector checks if colly refused the request, e.g. when following a redirect to a filtered url
+func isSkippedByCollector(err error) bool {
+	var alreadyVisitedError *colly.AlreadyVisitedError
+	return errors.As(err, &alreadyVisitedError) ||
+		errors.Is(err, colly.ErrForbiddenDomain) ||
+		errors.Is(err, colly.ErrForbiddenURL) ||
+		errors.Is(err, colly.ErrNoURLFiltersMatch) ||
+		errors.Is(err, colly.ErrRobotsTxtBlocked) ||
+		errors.Is(err, colly.ErrMaxDepth)
+}
+
 func isHtmlResponse(resp *colly.Response) bool {
-	contentType := strings.ToLower(resp.Headers.Get("Content-Type"))
-	return strings.Contains(contentType, "html")
+	return isHtmlContentType(resp.Headers.Get("Content-Type"))
+}
+
+func isHtmlContentType(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "html")
 }
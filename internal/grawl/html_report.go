@@ -0,0 +1,178 @@
+package grawl
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"time"
+)
+
+//go:embed templates/html_report.html
+var htmlReportTemplate string
+
+type htmlReport struct {
+	GrawlUrl     string
+	CreatedAt    string
+	Requests     int
+	Errors       int
+	Redirections int
+	Durations    []htmlReportDuration
+	StatusCodes  []htmlReportStatusCode
+	Results      []htmlReportResult
+	BrokenLinks  []htmlReportBrokenLinks
+	Redirects    []htmlReportResult
+}
+
+type htmlReportDuration struct {
+	Name       string
+	DurationMs int64
+}
+
+type htmlReportStatusCode struct {
+	StatusCode int
+	Status     string
+	Count      int
+	Percent    float64
+	IsError    bool
+}
+
+type htmlReportResult struct {
+	Index          uint32
+	ResponseTime   string
+	StatusCode     int
+	Status         string
+	Url            string
+	FoundOnUrl     string
+	ContentType    string
+	DurationMs     int64
+	Depth          int
+	RedirectedFrom string
+	RedirectChain  string
+	RedirectHops   int
+	Error          string
+	HasError       bool
+}
+
+type htmlReportBrokenLinks struct {
+	FoundOnUrl string
+	Results    []htmlReportResult
+}
+
+// writeHtmlReport writes a single html file with inlined css and js, so it can be used offline
+func writeHtmlReport(filePath string, grawlUrl string, results []*Result) error {
+	tmpl, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, newHtmlReport(grawlUrl, results))
+}
+
+func newHtmlReport(grawlUrl string, results []*Result) htmlReport {
+	report := htmlReport{
+		GrawlUrl:  grawlUrl,
+		CreatedAt: time.Now().Format(DateFormat),
+		Requests:  len(results),
+	}
+
+	durations := newDurationStats(sortedDurations(results, (*Result).GetDuration))
+	if durations.count() > 0 {
+		report.Durations = []htmlReportDuration{
+			{Name: "Min", DurationMs: durations.min().Milliseconds()},
+			{Name: "Max", DurationMs: durations.max().Milliseconds()},
+			{Name: "Avg", DurationMs: durations.avg.Milliseconds()},
+			{Name: "Std dev", DurationMs: durations.stdDev.Milliseconds()},
+		}
+		for _, percentile := range statisticsPercentiles {
+			report.Durations = append(report.Durations, htmlReportDuration{
+				Name:       fmt.Sprintf("p%g", percentile),
+				DurationMs: durations.percentile(percentile).Milliseconds(),
+			})
+		}
+	}
+
+	statusCodes := map[int]*htmlReportStatusCode{}
+	brokenLinks := map[string]*htmlReportBrokenLinks{}
+	foundOnUrls := make([]string, 0)
+
+	for _, result := range results {
+		reportResult := newHtmlReportResult(result)
+		report.Results = append(report.Results, reportResult)
+
+		statusCode, ok := statusCodes[result.statusCode]
+		if !ok {
+			statusCode = &htmlReportStatusCode{
+				StatusCode: result.statusCode,
+				Status:     result.status,
+				IsError:    result.statusCode == 0 || result.httpErrorCodeRanges.IsError(result.statusCode),
+			}
+			statusCodes[result.statusCode] = statusCode
+		}
+		statusCode.Count++
+
+		if result.HasRedirects() {
+			report.Redirections++
+			report.Redirects = append(report.Redirects, reportResult)
+		}
+
+		if !result.HasError() {
+			continue
+		}
+
+		report.Errors++
+		broken, ok := brokenLinks[result.foundOnUrl]
+		if !ok {
+			broken = &htmlReportBrokenLinks{FoundOnUrl: result.foundOnUrl}
+			brokenLinks[result.foundOnUrl] = broken
+			foundOnUrls = append(foundOnUrls, result.foundOnUrl)
+		}
+		broken.Results = append(broken.Results, reportResult)
+	}
+
+	for _, statusCode := range statusCodes {
+		statusCode.Percent = float64(statusCode.Count) * 100 / float64(len(results))
+		report.StatusCodes = append(report.StatusCodes, *statusCode)
+	}
+	sort.Slice(report.StatusCodes, func(i, j int) bool {
+		return report.StatusCodes[i].StatusCode < report.StatusCodes[j].StatusCode
+	})
+
+	sort.Strings(foundOnUrls)
+	for _, foundOnUrl := range foundOnUrls {
+		report.BrokenLinks = append(report.BrokenLinks, *brokenLinks[foundOnUrl])
+	}
+
+	return report
+}
+
+func newHtmlReportResult(result *Result) htmlReportResult {
+	errorText := ""
+	if result.error != nil {
+		errorText = result.error.Error()
+	}
+
+	return htmlReportResult{
+		Index:          result.Index,
+		ResponseTime:   result.responseAt.Format(DateFormat),
+		StatusCode:     result.statusCode,
+		Status:         result.status,
+		Url:            result.url,
+		FoundOnUrl:     result.foundOnUrl,
+		ContentType:    result.contentType,
+		DurationMs:     result.GetDuration().Milliseconds(),
+		Depth:          result.depth,
+		RedirectedFrom: result.urlRedirectedFrom,
+		RedirectChain:  result.GetRedirectChain(),
+		RedirectHops:   len(result.redirectChain),
+		Error:          errorText,
+		HasError:       result.HasError(),
+	}
+}
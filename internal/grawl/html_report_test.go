@@ -0,0 +1,175 @@
+package grawl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewHtmlReport(t *testing.T) {
+	redirected := newJunitTestResult(t, "a.com", "/new", "https://a.com/", 200)
+	redirected.AddRedirectHop("https://a.com/old", 301)
+	redirected.urlRedirectedFrom = "https://a.com/old"
+
+	failed := newJunitTestResult(t, "b.com", "/down", "https://a.com/", 0)
+	failed.error = errors.New("connection refused")
+
+	tests := []struct {
+		name                  string
+		results               []*Result
+		expectedErrors        int
+		expectedRedirections  int
+		expectedStatusCodes   []int
+		expectedPercents      []float64
+		expectedBrokenFoundOn []string
+		expectedDurations     bool
+	}{
+		{
+			name:                  "no results",
+			results:               []*Result{},
+			expectedStatusCodes:   []int{},
+			expectedPercents:      []float64{},
+			expectedBrokenFoundOn: []string{},
+		},
+		{
+			name: "results",
+			results: []*Result{
+				newJunitTestResult(t, "a.com", "/", "", 200),
+				redirected,
+				newJunitTestResult(t, "a.com", "/missing", "https://a.com/page", 404),
+				failed,
+			},
+			expectedErrors:        2,
+			expectedRedirections:  1,
+			expectedStatusCodes:   []int{0, 200, 404},
+			expectedPercents:      []float64{25, 50, 25},
+			expectedBrokenFoundOn: []string{"https://a.com/", "https://a.com/page"},
+			expectedDurations:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := newHtmlReport("https://a.com/", test.results)
+
+			if report.Requests != len(test.results) || report.Errors != test.expectedErrors || report.Redirections != test.expectedRedirections {
+				t.Errorf("expected %d requests, %d errors and %d redirections, got %d, %d and %d",
+					len(test.results), test.expectedErrors, test.expectedRedirections, report.Requests, report.Errors, report.Redirections)
+			}
+			if len(report.Redirects) != test.expectedRedirections {
+				t.Errorf("expected %d redirects, got %d", test.expectedRedirections, len(report.Redirects))
+			}
+			if (len(report.Durations) > 0) != test.expectedDurations {
+				t.Errorf("expected durations %v, got %v", test.expectedDurations, report.Durations)
+			}
+
+			statusCodes := make([]int, 0)
+			percents := make([]float64, 0)
+			for _, statusCode := range report.StatusCodes {
+				statusCodes = append(statusCodes, statusCode.StatusCode)
+				percents = append(percents, statusCode.Percent)
+				if expectedError := statusCode.StatusCode != 200; statusCode.IsError != expectedError {
+					t.Errorf("expected status code %d to be an error %v, got %v", statusCode.StatusCode, expectedError, statusCode.IsError)
+				}
+			}
+			if !slices.Equal(statusCodes, test.expectedStatusCodes) {
+				t.Errorf("expected status codes %v, got %v", test.expectedStatusCodes, statusCodes)
+			}
+			if !slices.Equal(percents, test.expectedPercents) {
+				t.Errorf("expected percents %v, got %v", test.expectedPercents, percents)
+			}
+
+			brokenFoundOn := make([]string, 0)
+			for _, broken := range report.BrokenLinks {
+				brokenFoundOn = append(brokenFoundOn, broken.FoundOnUrl)
+			}
+			if !slices.Equal(brokenFoundOn, test.expectedBrokenFoundOn) {
+				t.Errorf("expected broken links on %v, got %v", test.expectedBrokenFoundOn, brokenFoundOn)
+			}
+		})
+	}
+}
+
+func TestNewHtmlReportResult(t *testing.T) {
+	redirected := newJunitTestResult(t, "a.com", "/new", "https://a.com/", 200)
+	redirected.AddRedirectHop("https://a.com/old", 301)
+	redirected.urlRedirectedFrom = "https://a.com/old"
+
+	failed := newJunitTestResult(t, "a.com", "/down", "https://a.com/", 0)
+	failed.error = errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		result   *Result
+		expected htmlReportResult
+	}{
+		{
+			name:   "redirect",
+			result: redirected,
+			expected: htmlReportResult{
+				StatusCode:     200,
+				Status:         StatusAbbreviation(200),
+				Url:            "https://a.com/new",
+				FoundOnUrl:     "https://a.com/",
+				DurationMs:     250,
+				RedirectedFrom: "https://a.com/old",
+				RedirectChain:  "301 https://a.com/old -> 200 https://a.com/new",
+				RedirectHops:   1,
+			},
+		},
+		{
+			name:   "error",
+			result: failed,
+			expected: htmlReportResult{
+				Status:     StatusAbbreviation(0),
+				Url:        "https://a.com/down",
+				FoundOnUrl: "https://a.com/",
+				DurationMs: 250,
+				Error:      "connection refused",
+				HasError:   true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reportResult := newHtmlReportResult(test.result)
+
+			// The response time is formatted in the local time zone
+			reportResult.ResponseTime = ""
+			if reportResult != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, reportResult)
+			}
+		})
+	}
+}
+
+func TestWriteHtmlReport(t *testing.T) {
+	failed := newJunitTestResult(t, "a.com", "/missing", "https://a.com/", 404)
+	results := []*Result{newJunitTestResult(t, "a.com", "/", "", 200), failed}
+
+	filePath := filepath.Join(t.TempDir(), "report.html")
+	if err := writeHtmlReport(filePath, "https://a.com/<script>", results); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"<html", "https://a.com/missing", "https://a.com/&lt;script&gt;"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the report to contain %q", expected)
+		}
+	}
+	if strings.Contains(string(content), "https://a.com/<script>") {
+		t.Error("expected the grawl url to be escaped")
+	}
+
+	if err = writeHtmlReport(filepath.Join(t.TempDir(), "missing", "report.html"), "https://a.com/", results); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,247 @@
+package grawl
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JsonLinesWriter writes one json object per result and line
+type JsonLinesWriter struct {
+	sync.Mutex
+	filePath        string
+	fileInitialized bool
+	file            *os.File
+}
+
+type resultJson struct {
+	Index          uint32            `json:"index"`
+	RequestTime    string            `json:"request_time"`
+	ResponseTime   string            `json:"response_time"`
+	StatusCode     int               `json:"status_code"`
+	Status         string            `json:"status"`
+	Url            string            `json:"url"`
+	FoundOnUrl     string            `json:"found_on_url"`
+	ContentType    string            `json:"content_type"`
+	DurationMs     int64             `json:"duration_ms"`
+	DnsMs          int64             `json:"dns_ms"`
+	ConnectMs      int64             `json:"connect_ms"`
+	TlsMs          int64             `json:"tls_ms"`
+	TtfbMs         int64             `json:"ttfb_ms"`
+	DownloadMs     int64             `json:"download_ms"`
+	Depth          int               `json:"depth"`
+	RedirectedFrom string            `json:"redirected_from"`
+	RedirectChain  []redirectHopJson `json:"redirect_chain"`
+	RedirectStop   string            `json:"redirect_stopped_at,omitempty"`
+	RedirectReason string            `json:"redirect_stop_reason,omitempty"`
+	Location       string            `json:"location,omitempty"`
+	LastModified   string            `json:"last_modified,omitempty"`
+	Noindex        bool              `json:"noindex,omitempty"`
+	CanonicalUrl   string            `json:"canonical_url,omitempty"`
+	Host           string            `json:"host"`
+	Path           string            `json:"path"`
+	Parameters     string            `json:"parameters"`
+	Fragment       string            `json:"fragment"`
+	CacheStatus    string            `json:"cache_status,omitempty"`
+	CacheHeaders   []string          `json:"cache_headers,omitempty"`
+	WarmStatusCode int               `json:"warm_status_code,omitempty"`
+	WarmCache      string            `json:"warm_cache_status,omitempty"`
+	WarmDurationMs *int64            `json:"warm_duration_ms,omitempty"`
+	Attempts       int               `json:"attempts"`
+	RetriedErrors  []string          `json:"retried_errors,omitempty"`
+	Error          string            `json:"error"`
+	HasError       bool              `json:"has_error"`
+}
+
+func NewJsonLinesWriter(filePath string) *JsonLinesWriter {
+	return &JsonLinesWriter{
+		filePath:        filePath,
+		fileInitialized: false,
+	}
+}
+
+func (j *JsonLinesWriter) InitFile(out io.Writer, resume bool) error {
+	if j.fileInitialized {
+		return nil
+	}
+
+	file, _, err := openResultFile(out, j.filePath, resume)
+	if err != nil {
+		return err
+	}
+	j.file = file
+	j.fileInitialized = true
+	return nil
+}
+
+func (j *JsonLinesWriter) WriteResultLine(r *Result) error {
+	j.Lock()
+	defer j.Unlock()
+
+	if !j.fileInitialized {
+		panic("jsonl file not initialized yet")
+	}
+
+	// The file has already been closed after the grawling stopped
+	if j.file == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(newResultJson(r))
+	if err != nil {
+		return err
+	}
+
+	_, err = j.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (j *JsonLinesWriter) Close() error {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func newResultJson(r *Result) resultJson {
+	errorText := ""
+	if r.error != nil {
+		errorText = r.error.Error()
+	}
+
+	var warmDurationMs *int64
+	if r.warmed {
+		ms := r.warmDuration.Milliseconds()
+		warmDurationMs = &ms
+	}
+
+	return resultJson{
+		Index:          r.Index,
+		RequestTime:    formatJsonTime(r.requestAt),
+		ResponseTime:   formatJsonTime(r.responseAt),
+		StatusCode:     r.statusCode,
+		Status:         r.status,
+		Url:            r.url,
+		FoundOnUrl:     r.foundOnUrl,
+		ContentType:    r.contentType,
+		DurationMs:     r.GetDuration().Milliseconds(),
+		DnsMs:          r.timing.dns.Milliseconds(),
+		ConnectMs:      r.timing.connect.Milliseconds(),
+		TlsMs:          r.timing.tls.Milliseconds(),
+		TtfbMs:         r.timing.ttfb.Milliseconds(),
+		DownloadMs:     r.timing.download.Milliseconds(),
+		Depth:          r.depth,
+		RedirectedFrom: r.urlRedirectedFrom,
+		RedirectChain:  newRedirectHopsJson(r.redirectChain),
+		RedirectStop:   r.redirectStopUrl,
+		RedirectReason: r.redirectStopReason,
+		Location:       r.location,
+		LastModified:   formatJsonTime(r.lastModified),
+		Noindex:        r.noindex,
+		CanonicalUrl:   r.canonicalUrl,
+		Host:           r.urlHost,
+		Path:           r.urlPath,
+		Parameters:     r.urlParmeters,
+		Fragment:       r.urlFragment,
+		CacheStatus:    r.cacheStatus,
+		CacheHeaders:   r.cacheHeaders,
+		WarmStatusCode: r.warmStatusCode,
+		WarmCache:      r.warmCacheStatus,
+		WarmDurationMs: warmDurationMs,
+		Attempts:       r.GetAttempts(),
+		RetriedErrors:  r.retriedErrors,
+		Error:          errorText,
+		HasError:       r.HasError(),
+	}
+}
+
+// newResultFromJson restores a result, e.g. from a saved grawling state
+func newResultFromJson(j resultJson, httpErrorRanges *responseCodeRanges) (*Result, error) {
+	requestAt, err := parseJsonTime(j.RequestTime)
+	if err != nil {
+		return nil, err
+	}
+
+	responseAt, err := parseJsonTime(j.ResponseTime)
+	if err != nil {
+		return nil, err
+	}
+
+	initialRequestUrl := j.Url
+	if j.RedirectedFrom != "" {
+		initialRequestUrl = j.RedirectedFrom
+	}
+
+	result := NewResult(0, initialRequestUrl, j.FoundOnUrl, httpErrorRanges)
+	result.Index = j.Index
+	result.url = j.Url
+	result.urlHost = j.Host
+	result.urlPath = j.Path
+	result.urlParmeters = j.Parameters
+	result.urlFragment = j.Fragment
+	result.urlRedirectedFrom = j.RedirectedFrom
+	result.redirectChain = newRedirectHopsFromJson(j.RedirectChain)
+	result.redirectStopUrl = j.RedirectStop
+	result.redirectStopReason = j.RedirectReason
+	result.location = j.Location
+	result.noindex = j.Noindex
+	result.canonicalUrl = j.CanonicalUrl
+	result.retriedErrors = j.RetriedErrors
+	result.cacheStatus = j.CacheStatus
+	result.cacheHeaders = j.CacheHeaders
+	if j.WarmDurationMs != nil {
+		result.warmed = true
+		result.warmStatusCode = j.WarmStatusCode
+		result.warmCacheStatus = j.WarmCache
+		result.warmDuration = time.Duration(*j.WarmDurationMs) * time.Millisecond
+	}
+	result.timing = requestTiming{
+		dns:      time.Duration(j.DnsMs) * time.Millisecond,
+		connect:  time.Duration(j.ConnectMs) * time.Millisecond,
+		tls:      time.Duration(j.TlsMs) * time.Millisecond,
+		ttfb:     time.Duration(j.TtfbMs) * time.Millisecond,
+		download: time.Duration(j.DownloadMs) * time.Millisecond,
+	}
+	result.timing.newConnection = result.timing.hasConnectionPhases()
+	if result.lastModified, err = parseJsonTime(j.LastModified); err != nil {
+		return nil, err
+	}
+	result.requestAt = requestAt
+	result.responseAt = responseAt
+	result.statusCode = j.StatusCode
+	result.status = j.Status
+	result.statusShort = StatusAbbreviation(j.StatusCode)
+	result.contentType = j.ContentType
+	result.depth = j.Depth
+	result.updatedAtResponse = true
+
+	if j.Error != "" {
+		result.error = errors.New(j.Error)
+	}
+
+	return result, nil
+}
+
+func parseJsonTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func formatJsonTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
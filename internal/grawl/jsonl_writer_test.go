@@ -0,0 +1,180 @@
+package grawl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newJsonlTestResult returns a finished result of the url with the status code
+func newJsonlTestResult(t *testing.T, url string, statusCode int) *Result {
+	t.Helper()
+
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := NewResult(0, url, "https://example.com/", errorCodeRanges)
+	result.statusCode = statusCode
+	result.status = StatusAbbreviation(statusCode)
+	result.requestAt = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	result.responseAt = result.requestAt.Add(120 * time.Millisecond)
+	return result
+}
+
+// readJsonLines returns the urls of the lines of a jsonl file
+func readJsonLines(t *testing.T, filePath string) []string {
+	t.Helper()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	urls := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line resultJson
+		if err = json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		urls = append(urls, line.Url)
+	}
+	return urls
+}
+
+func TestJsonLinesWriter(t *testing.T) {
+	tests := []struct {
+		name           string
+		existingLines  []string
+		resume         bool
+		expectedUrls   []string
+		expectedOutput string
+	}{
+		{
+			name:           "new file",
+			expectedUrls:   []string{"https://example.com/a", "https://example.com/b"},
+			expectedOutput: "Saving file",
+		},
+		{
+			name:           "existing file is replaced",
+			existingLines:  []string{`{"url":"https://example.com/old"}`},
+			expectedUrls:   []string{"https://example.com/a", "https://example.com/b"},
+			expectedOutput: "Saving file",
+		},
+		{
+			name:           "existing file is continued on resume",
+			existingLines:  []string{`{"url":"https://example.com/old"}`},
+			resume:         true,
+			expectedUrls:   []string{"https://example.com/old", "https://example.com/a", "https://example.com/b"},
+			expectedOutput: "Continuing file",
+		},
+		{
+			name:           "missing file is created on resume",
+			resume:         true,
+			expectedUrls:   []string{"https://example.com/a", "https://example.com/b"},
+			expectedOutput: "Saving file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "results.jsonl")
+			if test.existingLines != nil {
+				if err := os.WriteFile(filePath, []byte(strings.Join(test.existingLines, "\n")+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			output := &bytes.Buffer{}
+			writer := NewJsonLinesWriter(filePath)
+			if err := writer.InitFile(output, test.resume); err != nil {
+				t.Fatal(err)
+			}
+			for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+				if err := writer.WriteResultLine(newJsonlTestResult(t, url, 200)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(output.String(), test.expectedOutput) {
+				t.Errorf("expected %q, got %q", test.expectedOutput, output)
+			}
+			if urls := readJsonLines(t, filePath); !slices.Equal(urls, test.expectedUrls) {
+				t.Errorf("expected %v, got %v", test.expectedUrls, urls)
+			}
+		})
+	}
+}
+
+func TestResultJson(t *testing.T) {
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirected := newJsonlTestResult(t, "https://example.com/a", 200)
+	redirected.AddRedirectHop("https://example.com/a", 301)
+	redirected.url = "https://example.com/b"
+	redirected.urlRedirectedFrom = "https://example.com/a"
+
+	failed := newJsonlTestResult(t, "https://example.com/c", 0)
+	failed.error = errors.New("connection refused")
+	failed.retriedErrors = []string{"timeout"}
+
+	tests := []struct {
+		name   string
+		result *Result
+	}{
+		{name: "page", result: newJsonlTestResult(t, "https://example.com/", 200)},
+		{name: "error status code", result: newJsonlTestResult(t, "https://example.com/missing", 404)},
+		{name: "redirect", result: redirected},
+		{name: "retried error without response", result: failed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			line, err := json.Marshal(newResultJson(test.result))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var parsed resultJson
+			if err = json.Unmarshal(line, &parsed); err != nil {
+				t.Fatal(err)
+			}
+			if parsed.HasError != test.result.HasError() {
+				t.Errorf("expected has_error %v, got %v", test.result.HasError(), parsed.HasError)
+			}
+			if parsed.DurationMs != 120 {
+				t.Errorf("expected a duration of 120ms, got %d", parsed.DurationMs)
+			}
+
+			restored, err := newResultFromJson(parsed, errorCodeRanges)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if restored.initialRequestUrl != test.result.initialRequestUrl {
+				t.Errorf("expected initial url %v, got %v", test.result.initialRequestUrl, restored.initialRequestUrl)
+			}
+			if restored.GetPrintRow() != test.result.GetPrintRow() {
+				t.Errorf("expected %v, got %v", test.result.GetPrintRow(), restored.GetPrintRow())
+			}
+			if restored.GetAttempts() != test.result.GetAttempts() {
+				t.Errorf("expected %d attempts, got %d", test.result.GetAttempts(), restored.GetAttempts())
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package grawl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	JunitGroupByHost    = "host"
+	JunitGroupByFoundOn = "found-on"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+func checkJunitGroupBy(groupBy string) error {
+	switch groupBy {
+	case JunitGroupByHost, JunitGroupByFoundOn:
+		return nil
+	default:
+		return fmt.Errorf("unknown junit group \"%s\": use \"%s\" or \"%s\"", groupBy, JunitGroupByHost, JunitGroupByFoundOn)
+	}
+}
+
+// writeJunitReport writes every result as test case. Results with errors are failures.
+func writeJunitReport(filePath string, groupBy string, grawlUrl string, results []*Result) error {
+	suitesByName := map[string]*junitTestSuite{}
+	suiteNames := make([]string, 0)
+	report := junitTestSuites{
+		Name: "grawler " + grawlUrl,
+	}
+
+	var totalDuration time.Duration
+	suiteDurations := map[string]time.Duration{}
+
+	for _, result := range results {
+		suiteName := getJunitSuiteName(result, groupBy)
+		suite, ok := suitesByName[suiteName]
+		if !ok {
+			suite = &junitTestSuite{
+				Name:      suiteName,
+				Timestamp: result.requestAt.Format("2006-01-02T15:04:05"),
+			}
+			suitesByName[suiteName] = suite
+			suiteNames = append(suiteNames, suiteName)
+		}
+
+		testCase := junitTestCase{
+			Name:      result.url,
+			ClassName: suiteName,
+			Time:      formatJunitSeconds(result.GetDuration()),
+		}
+
+		if result.HasError() {
+			testCase.Failure = newJunitFailure(result)
+			suite.Failures++
+			report.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		report.Tests++
+		suiteDurations[suiteName] += result.GetDuration()
+		totalDuration += result.GetDuration()
+	}
+
+	sort.Strings(suiteNames)
+	for _, suiteName := range suiteNames {
+		suite := suitesByName[suiteName]
+		suite.Time = formatJunitSeconds(suiteDurations[suiteName])
+		report.Suites = append(report.Suites, *suite)
+	}
+	report.Time = formatJunitSeconds(totalDuration)
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = file.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		return err
+	}
+
+	_, err = file.WriteString("\n")
+	return err
+}
+
+func getJunitSuiteName(result *Result, groupBy string) string {
+	if groupBy == JunitGroupByFoundOn {
+		if result.foundOnUrl == "" {
+			return "start url"
+		}
+		return result.foundOnUrl
+	}
+
+	if result.urlHost == "" {
+		return "unknown host"
+	}
+	return result.urlHost
+}
+
+func newJunitFailure(result *Result) *junitFailure {
+	message := fmt.Sprintf("%d %s", result.statusCode, result.status)
+	if result.error != nil {
+		message += ": " + result.error.Error()
+	}
+
+	text := []string{
+		"Url: " + result.url,
+		"Status code: " + strconv.Itoa(result.statusCode),
+		"Found on: " + result.foundOnUrl,
+	}
+
+	if result.HasRedirects() {
+		text = append(text, "Redirect chain: "+result.GetRedirectChain())
+	}
+
+	if len(result.retriedErrors) > 0 {
+		text = append(text, fmt.Sprintf("Attempts: %d (%s)", result.GetAttempts(), result.GetRetriedErrors()))
+	}
+
+	if result.error != nil {
+		text = append(text, "Error: "+result.error.Error())
+	}
+
+	return &junitFailure{
+		Message: message,
+		Type:    "HTTP " + strconv.Itoa(result.statusCode),
+		Text:    strings.Join(text, "\n"),
+	}
+}
+
+func formatJunitSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
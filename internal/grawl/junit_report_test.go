@@ -0,0 +1,178 @@
+package grawl
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newJunitTestResult returns a result of the url on the host with a duration of 250ms
+func newJunitTestResult(t *testing.T, host string, path string, foundOnUrl string, statusCode int) *Result {
+	t.Helper()
+
+	result := newJsonlTestResult(t, "https://"+host+path, statusCode)
+	result.urlHost = host
+	result.foundOnUrl = foundOnUrl
+	result.responseAt = result.requestAt.Add(250 * time.Millisecond)
+	return result
+}
+
+func TestCheckJunitGroupBy(t *testing.T) {
+	tests := []struct {
+		groupBy string
+		invalid bool
+	}{
+		{groupBy: JunitGroupByHost},
+		{groupBy: JunitGroupByFoundOn},
+		{groupBy: "", invalid: true},
+		{groupBy: "path", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.groupBy, func(t *testing.T) {
+			if err := checkJunitGroupBy(test.groupBy); (err != nil) != test.invalid {
+				t.Errorf("expected invalid %v, got %v", test.invalid, err)
+			}
+		})
+	}
+}
+
+func TestWriteJunitReport(t *testing.T) {
+	failed := newJunitTestResult(t, "b.com", "/down", "https://a.com/", 0)
+	failed.error = errors.New("connection refused")
+
+	results := []*Result{
+		newJunitTestResult(t, "b.com", "/", "", 200),
+		newJunitTestResult(t, "a.com", "/", "", 200),
+		newJunitTestResult(t, "a.com", "/missing", "https://a.com/", 404),
+		failed,
+	}
+
+	tests := []struct {
+		name             string
+		groupBy          string
+		expectedSuites   []string
+		expectedTests    []int
+		expectedFailures []int
+	}{
+		{
+			name:             "grouped by host",
+			groupBy:          JunitGroupByHost,
+			expectedSuites:   []string{"a.com", "b.com"},
+			expectedTests:    []int{2, 2},
+			expectedFailures: []int{1, 1},
+		},
+		{
+			name:             "grouped by found on url",
+			groupBy:          JunitGroupByFoundOn,
+			expectedSuites:   []string{"https://a.com/", "start url"},
+			expectedTests:    []int{2, 2},
+			expectedFailures: []int{2, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "report.xml")
+			if err := writeJunitReport(filePath, test.groupBy, "https://a.com/", results); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(content), xml.Header) {
+				t.Errorf("expected the xml header, got %q", content)
+			}
+
+			var report junitTestSuites
+			if err = xml.Unmarshal(content, &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Name != "grawler https://a.com/" || report.Tests != 4 || report.Failures != 2 || report.Time != "1.000" {
+				t.Errorf("expected 4 tests, 2 failures and 1.000s, got %+v", report)
+			}
+
+			suiteNames := make([]string, 0)
+			for i, suite := range report.Suites {
+				suiteNames = append(suiteNames, suite.Name)
+				if i < len(test.expectedTests) && (suite.Tests != test.expectedTests[i] || suite.Failures != test.expectedFailures[i]) {
+					t.Errorf("expected suite %s with %d tests and %d failures, got %d and %d",
+						suite.Name, test.expectedTests[i], test.expectedFailures[i], suite.Tests, suite.Failures)
+				}
+				if suite.Time != "0.500" {
+					t.Errorf("expected suite %s to take 0.500s, got %s", suite.Name, suite.Time)
+				}
+			}
+			if !slices.Equal(suiteNames, test.expectedSuites) {
+				t.Errorf("expected suites %v, got %v", test.expectedSuites, suiteNames)
+			}
+		})
+	}
+}
+
+func TestNewJunitFailure(t *testing.T) {
+	redirected := newJunitTestResult(t, "a.com", "/new", "https://a.com/", 404)
+	redirected.AddRedirectHop("https://a.com/old", 301)
+
+	retried := newJunitTestResult(t, "a.com", "/down", "https://a.com/", 0)
+	retried.error = errors.New("connection refused")
+	retried.retriedErrors = []string{"timeout"}
+
+	tests := []struct {
+		name            string
+		result          *Result
+		expectedMessage string
+		expectedType    string
+		expectedText    []string
+	}{
+		{
+			name:            "error status code",
+			result:          newJunitTestResult(t, "a.com", "/missing", "https://a.com/", 404),
+			expectedMessage: "404 " + StatusAbbreviation(404),
+			expectedType:    "HTTP 404",
+			expectedText:    []string{"Url: https://a.com/missing", "Status code: 404", "Found on: https://a.com/"},
+		},
+		{
+			name:            "redirect",
+			result:          redirected,
+			expectedMessage: "404 " + StatusAbbreviation(404),
+			expectedType:    "HTTP 404",
+			expectedText: []string{
+				"Url: https://a.com/new", "Status code: 404", "Found on: https://a.com/",
+				"Redirect chain: 301 https://a.com/old -> 404 https://a.com/new",
+			},
+		},
+		{
+			name:            "retried error",
+			result:          retried,
+			expectedMessage: "0 " + StatusAbbreviation(0) + ": connection refused",
+			expectedType:    "HTTP 0",
+			expectedText: []string{
+				"Url: https://a.com/down", "Status code: 0", "Found on: https://a.com/",
+				"Attempts: 2 (timeout)", "Error: connection refused",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			failure := newJunitFailure(test.result)
+			if failure.Message != test.expectedMessage {
+				t.Errorf("expected message %q, got %q", test.expectedMessage, failure.Message)
+			}
+			if failure.Type != test.expectedType {
+				t.Errorf("expected type %q, got %q", test.expectedType, failure.Type)
+			}
+			if text := strings.Split(failure.Text, "\n"); !slices.Equal(text, test.expectedText) {
+				t.Errorf("expected %q, got %q", test.expectedText, text)
+			}
+		})
+	}
+}
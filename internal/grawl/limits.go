@@ -0,0 +1,116 @@
+package grawl
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gobwas/glob"
+	"github.com/gocolly/colly/v2"
+	"time"
+)
+
+const defaultLimitDomainGlob = "*"
+
+// LimitRule limits the parallel requests and the delay for the hosts matching the domain glob. Values which are
+// not set are taken from the parallel, delay and random delay flags.
+type LimitRule struct {
+	DomainGlob  string `mapstructure:"domain-glob" json:"domain_glob"`
+	Parallel    *int   `mapstructure:"parallel" json:"parallel"`
+	Delay       *int64 `mapstructure:"delay" json:"delay"`
+	RandomDelay *int64 `mapstructure:"random-delay" json:"random_delay"`
+	// domainGlob is compiled once by EffectiveLimitRules
+	domainGlob glob.Glob
+}
+
+func (r LimitRule) String() string {
+	return fmt.Sprintf(
+		"%s (parallel: %d, delay: %dms, random delay: %dms)",
+		r.DomainGlob,
+		valueOf(r.Parallel),
+		valueOf(r.Delay),
+		valueOf(r.RandomDelay),
+	)
+}
+
+// EffectiveLimitRules returns the limit rules of the configuration with the values taken from the flags and
+// the rule for all other hosts at the end. The first rule matching a host is used.
+func EffectiveLimitRules(flags Flags) ([]LimitRule, error) {
+	rules := make([]LimitRule, 0, len(flags.FlagLimitRules)+1)
+
+	for _, rule := range flags.FlagLimitRules {
+		if rule.DomainGlob == "" {
+			return nil, errors.New("limit rule without domain-glob")
+		}
+		domainGlob, err := glob.Compile(rule.DomainGlob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid domain-glob \"%s\" of limit rule: %v", rule.DomainGlob, err)
+		}
+		rule.domainGlob = domainGlob
+		if valueOf(rule.Parallel) < 0 || valueOf(rule.Delay) < 0 || valueOf(rule.RandomDelay) < 0 {
+			return nil, fmt.Errorf("limit rule \"%s\" has negative values", rule.DomainGlob)
+		}
+
+		if rule.Parallel == nil {
+			rule.Parallel = &flags.FlagParallel
+		}
+
+		// The delays are taken together, so a rule with a delay does not get the random delay of the flags
+		if rule.Delay == nil && rule.RandomDelay == nil {
+			rule.Delay, rule.RandomDelay = flagDelays(flags)
+		}
+		if rule.Delay == nil {
+			rule.Delay = new(int64)
+		}
+		if rule.RandomDelay == nil {
+			rule.RandomDelay = new(int64)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	delay, randomDelay := flagDelays(flags)
+	rules = append(rules, LimitRule{
+		DomainGlob:  defaultLimitDomainGlob,
+		Parallel:    &flags.FlagParallel,
+		Delay:       delay,
+		RandomDelay: randomDelay,
+		domainGlob:  glob.MustCompile(defaultLimitDomainGlob),
+	})
+
+	return rules, nil
+}
+
+// matches checks the host against the compiled domain glob
+func (r LimitRule) matches(host string) bool {
+	return r.domainGlob != nil && r.domainGlob.Match(host)
+}
+
+// flagDelays returns the delays of the flags, a random delay replaces the fixed delay
+func flagDelays(flags Flags) (*int64, *int64) {
+	delay := flags.FlagDelay
+	randomDelay := flags.FlagRandomDelay
+	if randomDelay > 0 {
+		delay = 0
+	}
+	return &delay, &randomDelay
+}
+
+// newCollyLimitRules converts the limit rules, colly matches the glob against the host of the url including the port
+func newCollyLimitRules(rules []LimitRule) []*colly.LimitRule {
+	collyRules := make([]*colly.LimitRule, 0, len(rules))
+	for _, rule := range rules {
+		collyRules = append(collyRules, &colly.LimitRule{
+			DomainGlob:  rule.DomainGlob,
+			Parallelism: valueOf(rule.Parallel),
+			Delay:       time.Duration(valueOf(rule.Delay)) * time.Millisecond,
+			RandomDelay: time.Duration(valueOf(rule.RandomDelay)) * time.Millisecond,
+		})
+	}
+	return collyRules
+}
+
+func valueOf[T int | int64](value *T) T {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,166 @@
+package grawl
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// ptr returns a pointer to the value, e.g. for the optional values of a limit rule
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestEffectiveLimitRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    Flags
+		expected []string
+		invalid  bool
+	}{
+		{
+			name:     "flags only",
+			flags:    Flags{FlagParallel: 2, FlagDelay: 100},
+			expected: []string{"* (parallel: 2, delay: 100ms, random delay: 0ms)"},
+		},
+		{
+			name:     "random delay replaces the delay",
+			flags:    Flags{FlagParallel: 2, FlagDelay: 100, FlagRandomDelay: 300},
+			expected: []string{"* (parallel: 2, delay: 0ms, random delay: 300ms)"},
+		},
+		{
+			name: "rule values",
+			flags: Flags{
+				FlagParallel: 2,
+				FlagLimitRules: []LimitRule{
+					{DomainGlob: "*.example.com", Parallel: ptr(1), Delay: ptr(int64(500)), RandomDelay: ptr(int64(200))},
+				},
+			},
+			expected: []string{
+				"*.example.com (parallel: 1, delay: 500ms, random delay: 200ms)",
+				"* (parallel: 2, delay: 0ms, random delay: 0ms)",
+			},
+		},
+		{
+			name: "rule values taken from the flags",
+			flags: Flags{
+				FlagParallel:    4,
+				FlagRandomDelay: 300,
+				FlagLimitRules:  []LimitRule{{DomainGlob: "example.com"}},
+			},
+			expected: []string{
+				"example.com (parallel: 4, delay: 0ms, random delay: 300ms)",
+				"* (parallel: 4, delay: 0ms, random delay: 300ms)",
+			},
+		},
+		{
+			name: "rule delay without the random delay of the flags",
+			flags: Flags{
+				FlagParallel:    1,
+				FlagRandomDelay: 300,
+				FlagLimitRules:  []LimitRule{{DomainGlob: "example.com", Delay: ptr(int64(50))}},
+			},
+			expected: []string{
+				"example.com (parallel: 1, delay: 50ms, random delay: 0ms)",
+				"* (parallel: 1, delay: 0ms, random delay: 300ms)",
+			},
+		},
+		{
+			name:    "rule without domain glob",
+			flags:   Flags{FlagLimitRules: []LimitRule{{Parallel: ptr(1)}}},
+			invalid: true,
+		},
+		{
+			name:    "invalid domain glob",
+			flags:   Flags{FlagLimitRules: []LimitRule{{DomainGlob: "[example.com"}}},
+			invalid: true,
+		},
+		{
+			name:    "negative value",
+			flags:   Flags{FlagLimitRules: []LimitRule{{DomainGlob: "example.com", Delay: ptr(int64(-1))}}},
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := EffectiveLimitRules(test.flags)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %v", rules)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			formatted := make([]string, 0, len(rules))
+			for _, rule := range rules {
+				formatted = append(formatted, rule.String())
+			}
+			if !slices.Equal(formatted, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestLimitRuleMatches(t *testing.T) {
+	rules, err := EffectiveLimitRules(Flags{
+		FlagParallel: 1,
+		FlagLimitRules: []LimitRule{
+			{DomainGlob: "*.example.com"},
+			{DomainGlob: "example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host     string
+		expected []bool
+	}{
+		{host: "www.example.com", expected: []bool{true, false, true}},
+		{host: "example.com", expected: []bool{false, true, true}},
+		{host: "other.com", expected: []bool{false, false, true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			matches := make([]bool, 0, len(rules))
+			for _, rule := range rules {
+				matches = append(matches, rule.matches(test.host))
+			}
+			if !slices.Equal(matches, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, matches)
+			}
+		})
+	}
+
+	if (LimitRule{DomainGlob: "*"}).matches("example.com") {
+		t.Error("expected a rule without compiled glob not to match")
+	}
+}
+
+func TestNewCollyLimitRules(t *testing.T) {
+	rules := []LimitRule{
+		{DomainGlob: "example.com", Parallel: ptr(3), Delay: ptr(int64(250)), RandomDelay: ptr(int64(100))},
+		{DomainGlob: "*"},
+	}
+
+	collyRules := newCollyLimitRules(rules)
+	if len(collyRules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(collyRules))
+	}
+
+	first := collyRules[0]
+	if first.DomainGlob != "example.com" || first.Parallelism != 3 || first.Delay != 250*time.Millisecond || first.RandomDelay != 100*time.Millisecond {
+		t.Errorf("expected the values of %v, got %+v", rules[0], first)
+	}
+	last := collyRules[1]
+	if last.DomainGlob != "*" || last.Parallelism != 0 || last.Delay != 0 || last.RandomDelay != 0 {
+		t.Errorf("expected zero values for unset values, got %+v", last)
+	}
+}
@@ -0,0 +1,234 @@
+package grawl
+
+import (
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"slices"
+	"strings"
+)
+
+// linkExtractor finds the urls of a html element type
+type linkExtractor struct {
+	name     string
+	selector string
+	extract  func(e *colly.HTMLElement, flags Flags) []string
+}
+
+// DefaultFollowElements are the element types whose urls are grawled and searched for further links by default
+var DefaultFollowElements = []string{"a"}
+
+var linkRelations = []string{"alternate", "canonical", "next", "prev", "preload", "icon"}
+
+var linkExtractors = []linkExtractor{
+	{
+		name:     "a",
+		selector: "a[href]",
+		extract:  extractHyperlink,
+	},
+	{
+		name:     "area",
+		selector: "area[href]",
+		extract:  extractHyperlink,
+	},
+	{
+		name:     "iframe",
+		selector: "iframe[src]",
+		extract:  extractAttrs("src"),
+	},
+	{
+		name:     "link",
+		selector: "link[rel][href]",
+		extract: func(e *colly.HTMLElement, flags Flags) []string {
+			for _, rel := range strings.Fields(strings.ToLower(e.Attr("rel"))) {
+				if slices.Contains(linkRelations, rel) {
+					return []string{e.Attr("href")}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		name:     "stylesheet",
+		selector: "link[rel='stylesheet'][href]",
+		extract:  extractAttrs("href"),
+	},
+	{
+		name:     "img",
+		selector: "img[src], img[srcset]",
+		extract:  extractAttrs("src", "srcset"),
+	},
+	{
+		name:     "source",
+		selector: "source[src], source[srcset]",
+		extract:  extractAttrs("src", "srcset"),
+	},
+	{
+		name:     "script",
+		selector: "script[src]",
+		extract:  extractAttrs("src"),
+	},
+	{
+		name:     "media",
+		selector: "video[src], video[poster], audio[src]",
+		extract:  extractAttrs("src", "poster"),
+	},
+	{
+		name:     "object",
+		selector: "object[data]",
+		extract:  extractAttrs("data"),
+	},
+	{
+		name:     "form",
+		selector: "form[action]",
+		extract: func(e *colly.HTMLElement, flags Flags) []string {
+			// Only GET forms can be requested without sending any data
+			method := strings.ToUpper(strings.TrimSpace(e.Attr("method")))
+			if method != "" && method != "GET" {
+				return nil
+			}
+			return []string{e.Attr("action")}
+		},
+	},
+	{
+		name:     "meta-refresh",
+		selector: "meta[http-equiv][content]",
+		extract: func(e *colly.HTMLElement, flags Flags) []string {
+			if !strings.EqualFold(e.Attr("http-equiv"), "refresh") {
+				return nil
+			}
+			return []string{parseMetaRefreshUrl(e.Attr("content"))}
+		},
+	},
+}
+
+// LinkExtractorNames returns the names of all element types links can be extracted from
+func LinkExtractorNames() []string {
+	names := make([]string, 0, len(linkExtractors))
+	for _, extractor := range linkExtractors {
+		names = append(names, extractor.name)
+	}
+	return names
+}
+
+// checkLinkExtractorNames validates the element types of --follow-elements and --check-elements
+func checkLinkExtractorNames(names []string) error {
+	validNames := LinkExtractorNames()
+	for _, name := range names {
+		if !slices.Contains(validNames, name) {
+			return fmt.Errorf("unknown element type \"%s\", valid element types are: %s", name, strings.Join(validNames, ", "))
+		}
+	}
+	return nil
+}
+
+// linkExtractorsOf returns the extractors whose urls are followed and the extractors whose urls are only checked
+func linkExtractorsOf(flags Flags) (follow []linkExtractor, check []linkExtractor) {
+	followNames := flags.FlagFollowElements
+	if len(followNames) == 0 {
+		followNames = DefaultFollowElements
+	}
+
+	checkNames := flags.FlagCheckElements
+	if flags.FlagCheckAll {
+		checkNames = LinkExtractorNames()
+	}
+
+	for _, extractor := range linkExtractors {
+		if slices.Contains(followNames, extractor.name) {
+			follow = append(follow, extractor)
+		} else if slices.Contains(checkNames, extractor.name) {
+			check = append(check, extractor)
+		}
+	}
+	return follow, check
+}
+
+// registerLinkExtractors adds the html callbacks which visit the found urls
+func (g *Grawler) registerLinkExtractors(c *colly.Collector) {
+	follow, check := linkExtractorsOf(g.flags)
+
+	// The pages of a sitemap are not searched for further links
+	if g.flags.FlagSitemap {
+		follow = nil
+	}
+
+	register := func(extractor linkExtractor, followLinks bool) {
+		c.OnHTML(extractor.selector, func(e *colly.HTMLElement) {
+			for _, link := range extractor.extract(e, g.flags) {
+				if !isGrawlableLink(link) {
+					continue
+				}
+				g.visit(c, e.Request, e.Request.AbsoluteURL(link), e.Request.URL.String(), followLinks)
+			}
+		})
+	}
+
+	for _, extractor := range follow {
+		register(extractor, true)
+	}
+	for _, extractor := range check {
+		register(extractor, false)
+	}
+}
+
+// extractHyperlink returns the href of a hyperlink, respecting rel="nofollow" with --respect-nofollow
+func extractHyperlink(e *colly.HTMLElement, flags Flags) []string {
+	if flags.FlagRespectNofollow && slices.Contains(strings.Fields(strings.ToLower(e.Attr("rel"))), "nofollow") {
+		return nil
+	}
+	return []string{e.Attr("href")}
+}
+
+// extractAttrs returns the urls of the given attributes, srcset attributes may contain multiple urls
+func extractAttrs(attrs ...string) func(e *colly.HTMLElement, flags Flags) []string {
+	return func(e *colly.HTMLElement, flags Flags) []string {
+		links := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			value := e.Attr(attr)
+			if attr == "srcset" {
+				links = append(links, parseSrcset(value)...)
+			} else if value != "" {
+				links = append(links, value)
+			}
+		}
+		return links
+	}
+}
+
+// parseSrcset returns the urls of the image candidates, e.g. "a.jpg 1x, b.jpg 2x"
+func parseSrcset(srcset string) []string {
+	links := make([]string, 0)
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 {
+			links = append(links, fields[0])
+		}
+	}
+	return links
+}
+
+// parseMetaRefreshUrl returns the url of a meta refresh content, e.g. "5; url=https://example.com"
+func parseMetaRefreshUrl(content string) string {
+	for _, part := range strings.Split(content, ";") {
+		part = strings.TrimSpace(part)
+		if len(part) > 4 && strings.EqualFold(part[:4], "url=") {
+			return strings.Trim(strings.TrimSpace(part[4:]), `"'`)
+		}
+	}
+	return ""
+}
+
+// isGrawlableLink skips empty links and links which can not be requested
+func isGrawlableLink(link string) bool {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return false
+	}
+
+	for _, scheme := range []string{"mailto:", "tel:", "javascript:", "data:"} {
+		if len(link) >= len(scheme) && strings.EqualFold(link[:len(scheme)], scheme) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package grawl
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		name     string
+		srcset   string
+		expected []string
+	}{
+		{name: "single url", srcset: "a.jpg", expected: []string{"a.jpg"}},
+		{name: "densities", srcset: "a.jpg 1x, b.jpg 2x", expected: []string{"a.jpg", "b.jpg"}},
+		{name: "widths and whitespace", srcset: " /a.jpg  480w ,\n/b.jpg 800w ", expected: []string{"/a.jpg", "/b.jpg"}},
+		{name: "empty candidates", srcset: "a.jpg 1x,, ", expected: []string{"a.jpg"}},
+		{name: "empty", srcset: "", expected: []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if links := parseSrcset(test.srcset); !slices.Equal(links, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, links)
+			}
+		})
+	}
+}
+
+func TestParseMetaRefreshUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "delay and url", content: "5; url=https://example.com", expected: "https://example.com"},
+		{name: "upper case", content: "0;URL=/next", expected: "/next"},
+		{name: "quoted url", content: `0; url='/next?a=1'`, expected: "/next?a=1"},
+		{name: "double quoted url", content: `0; url="/next"`, expected: "/next"},
+		{name: "delay only", content: "5", expected: ""},
+		{name: "empty url", content: "5; url=", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if link := parseMetaRefreshUrl(test.content); link != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, link)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package grawl
+
+import (
+	"errors"
+	"sync"
+)
+
+// errRequestPaused is returned for requests which would be sent while the grawling is paused. They are requested
+// again and wait for the end of the pause in OnRequest, so the pause does not count for the request timeout.
+var errRequestPaused = errors.New("grawling paused")
+
+// pauseGate holds back new requests while the grawling is paused. Running requests are finished.
+// The zero value is an unpaused gate.
+type pauseGate struct {
+	mutex   sync.Mutex
+	paused  bool
+	resumed chan struct{}
+}
+
+// pause pauses the grawling and returns false if it has already been paused
+func (p *pauseGate) pause() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.paused {
+		return false
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+	return true
+}
+
+// resume continues the grawling and returns false if it has not been paused
+func (p *pauseGate) resume() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.paused {
+		return false
+	}
+	p.paused = false
+	close(p.resumed)
+	return true
+}
+
+func (p *pauseGate) isPaused() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.paused
+}
+
+// wait blocks while the grawling is paused. It returns false if the grawling has been stopped meanwhile.
+func (p *pauseGate) wait(stopped <-chan struct{}) bool {
+	p.mutex.Lock()
+	paused, resumed := p.paused, p.resumed
+	p.mutex.Unlock()
+
+	if !paused {
+		return true
+	}
+
+	select {
+	case <-resumed:
+		return true
+	case <-stopped:
+		return false
+	}
+}
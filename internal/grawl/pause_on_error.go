@@ -0,0 +1,217 @@
+package grawl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	errorDecisionRetry        = "r"
+	errorDecisionSkip         = "s"
+	errorDecisionAbort        = "a"
+	errorDecisionIgnoreStatus = "i"
+	errorDecisionIgnorePath   = "p"
+)
+
+// errorController pauses the grawling on errors of --pause-on-error and asks for a decision about one error
+// after another. The callbacks of colly queue the errors, only the callback of the first error waits until all
+// errors are decided. It keeps the collector running for the retries of the decisions, but holds no slot of the limits.
+type errorController struct {
+	grawler       *Grawler
+	input         *bufio.Reader
+	mutex         sync.Mutex
+	pending       []*Result
+	prompting     bool
+	idle          chan struct{}
+	ignoredStatus map[int]bool
+	ignoredPaths  []string
+	disabled      bool
+}
+
+func newErrorController(g *Grawler, input io.Reader) *errorController {
+	return &errorController{
+		grawler:       g,
+		input:         bufio.NewReader(input),
+		ignoredStatus: map[int]bool{},
+	}
+}
+
+// add queues an error and pauses the grawling until all queued errors are decided
+func (c *errorController) add(result *Result) {
+	c.mutex.Lock()
+	if c.disabled || c.isIgnored(result) {
+		c.mutex.Unlock()
+		return
+	}
+
+	c.pending = append(c.pending, result)
+	if c.prompting {
+		c.mutex.Unlock()
+		return
+	}
+
+	c.prompting = true
+	c.idle = make(chan struct{})
+	idle := c.idle
+	c.grawler.pause.pause()
+	c.grawler.hideStatusBar()
+	go c.promptPending()
+	c.mutex.Unlock()
+
+	select {
+	case <-idle:
+	case <-c.grawler.stopped:
+	}
+}
+
+// waitIdle blocks while errors are being decided. It returns true if it has waited.
+func (c *errorController) waitIdle(stopped <-chan struct{}) bool {
+	c.mutex.Lock()
+	prompting, idle := c.prompting, c.idle
+	c.mutex.Unlock()
+
+	if !prompting {
+		return false
+	}
+
+	select {
+	case <-idle:
+		return true
+	case <-stopped:
+		return false
+	}
+}
+
+// isIgnored checks if the status or the path of the error has been ignored. The mutex has to be locked.
+func (c *errorController) isIgnored(result *Result) bool {
+	if c.ignoredStatus[result.errorStatusCode()] {
+		return true
+	}
+
+	for _, path := range c.ignoredPaths {
+		if strings.HasPrefix(result.url, path) || strings.HasPrefix(result.initialRequestUrl, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptPending asks for the queued errors and resumes the grawling when all errors are decided
+func (c *errorController) promptPending() {
+	for {
+		c.mutex.Lock()
+		if len(c.pending) == 0 || c.grawler.isStopping() {
+			c.pending = nil
+			c.prompting = false
+			close(c.idle)
+			c.grawler.showStatusBar()
+			if !c.grawler.isStopping() && c.grawler.pause.resume() {
+				fmt.Fprintln(c.grawler.out(), "Grawling resumed.")
+			}
+			c.mutex.Unlock()
+			return
+		}
+
+		result := c.pending[0]
+		c.pending = c.pending[1:]
+		ignored := c.isIgnored(result)
+		waiting := len(c.pending)
+		c.mutex.Unlock()
+
+		if ignored {
+			continue
+		}
+
+		decision, err := c.prompt(result, waiting)
+		if err != nil {
+			fmt.Fprintln(c.grawler.out(), "Could not read the decision, pause on error is disabled:", err)
+			c.mutex.Lock()
+			c.disabled = true
+			c.pending = nil
+			c.mutex.Unlock()
+			continue
+		}
+		c.decide(result, decision)
+	}
+}
+
+func (c *errorController) prompt(result *Result, waiting int) (string, error) {
+	fmt.Fprintf(c.grawler.out(), "Pause grawling after error of %s: %s\n", result.url, attemptError(result.statusCode, result.error))
+	if waiting > 0 {
+		fmt.Fprintf(c.grawler.out(), "%d more errors are waiting for a decision.\n", waiting)
+	}
+
+	for {
+		fmt.Fprintf(c.grawler.out(),
+			"Please choose: [r]etry the url, [s]kip the url, [a]bort the grawling, [i]gnore %s for the rest of the grawling or ignore errors under the [p]ath %s.\n",
+			errorStatusLabel(result.errorStatusCode()),
+			ignoredPathOf(result.url),
+		)
+
+		line, err := c.input.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+
+		switch decision := strings.ToLower(strings.TrimSpace(line)); decision {
+		case errorDecisionRetry, errorDecisionSkip, errorDecisionAbort, errorDecisionIgnoreStatus, errorDecisionIgnorePath:
+			return decision, nil
+		}
+	}
+}
+
+func (c *errorController) decide(result *Result, decision string) {
+	switch decision {
+	case errorDecisionRetry:
+		if err := c.grawler.retryFailedUrl(result.initialRequestUrl); err != nil {
+			fmt.Fprintf(c.grawler.out(), "Could not retry %s: %v\n", result.initialRequestUrl, err)
+			return
+		}
+		fmt.Fprintln(c.grawler.out(), "Retry url...")
+	case errorDecisionSkip:
+		fmt.Fprintln(c.grawler.out(), "Url skipped.")
+	case errorDecisionAbort:
+		c.grawler.stop(ExitCodeErrorsFound, "Grawling aborted.")
+	case errorDecisionIgnoreStatus:
+		c.mutex.Lock()
+		c.ignoredStatus[result.errorStatusCode()] = true
+		c.mutex.Unlock()
+		fmt.Fprintf(c.grawler.out(), "Errors with %s do not pause the grawling anymore.\n", errorStatusLabel(result.errorStatusCode()))
+	case errorDecisionIgnorePath:
+		path := ignoredPathOf(result.url)
+		c.mutex.Lock()
+		c.ignoredPaths = append(c.ignoredPaths, path)
+		c.mutex.Unlock()
+		fmt.Fprintf(c.grawler.out(), "Errors under %s do not pause the grawling anymore.\n", path)
+	}
+}
+
+// errorStatusLabel describes the status code of an error, 0 are errors without a response
+func errorStatusLabel(statusCode int) string {
+	if statusCode == 0 {
+		return "errors without status code"
+	}
+	return fmt.Sprintf("status %d", statusCode)
+}
+
+// ignoredPathOf returns the url up to the last slash of its path, e.g. "https://a.com/docs/" of "https://a.com/docs/page"
+func ignoredPathOf(rawUrl string) string {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	parsedUrl.RawQuery = ""
+	parsedUrl.Fragment = ""
+	parsedUrl.RawFragment = ""
+	parsedUrl.RawPath = ""
+	parsedUrl.Path = parsedUrl.Path[:strings.LastIndex(parsedUrl.Path, "/")+1]
+	if parsedUrl.Path == "" {
+		parsedUrl.Path = "/"
+	}
+	return parsedUrl.String()
+}
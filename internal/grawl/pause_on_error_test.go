@@ -0,0 +1,216 @@
+package grawl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncBuffer collects the output of parallel requests
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+// slowReader returns the decisions after a delay, like a user who takes time to answer
+type slowReader struct {
+	delay  time.Duration
+	reader io.Reader
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	return r.reader.Read(p)
+}
+
+// newPauseOnErrorTestServer serves a start page linking the paths. Paths starting with /missing or /docs/missing
+// are not found, /flaky fails once and then succeeds.
+func newPauseOnErrorTestServer(t *testing.T, paths []string) *httptest.Server {
+	t.Helper()
+
+	var flakyRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch {
+		case r.URL.Path == "/":
+			fmt.Fprint(w, "<html><body>")
+			for _, path := range paths {
+				fmt.Fprintf(w, `<a href="%s">link</a>`, path)
+			}
+			fmt.Fprint(w, "</body></html>")
+		case strings.HasPrefix(r.URL.Path, "/missing"), strings.HasPrefix(r.URL.Path, "/docs/missing"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/flaky" && flakyRequests.Add(1) == 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, "<html><body></body></html>")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPauseOnError(t *testing.T) {
+	tests := []struct {
+		name             string
+		paths            []string
+		input            string
+		delay            time.Duration
+		expectedPrompts  int
+		expectedOutput   []string
+		expectedErrors   []string
+		expectedExitCode ExitCode
+		expectedResumed  bool
+	}{
+		{
+			name:             "retry",
+			paths:            []string{"/flaky", "/a"},
+			input:            "r\n",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Retry url..."},
+			expectedErrors:   []string{},
+			expectedExitCode: ExitCodeOk,
+			expectedResumed:  true,
+		},
+		{
+			name:             "skip",
+			paths:            []string{"/missing", "/a"},
+			input:            "s\n",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Url skipped."},
+			expectedErrors:   []string{"/missing"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  true,
+		},
+		{
+			name:             "abort",
+			paths:            []string{"/missing", "/a"},
+			input:            "a\n",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Grawling aborted."},
+			expectedErrors:   []string{"/missing"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  false,
+		},
+		{
+			name:             "ignore the status",
+			paths:            []string{"/missing-1", "/missing-2", "/a"},
+			input:            "i\n",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Errors with status 404 do not pause the grawling anymore."},
+			expectedErrors:   []string{"/missing-1", "/missing-2"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  true,
+		},
+		{
+			name:             "ignore the path",
+			paths:            []string{"/docs/missing-1", "/docs/missing-2", "/a"},
+			input:            "p\n",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"/docs/ do not pause the grawling anymore."},
+			expectedErrors:   []string{"/docs/missing-1", "/docs/missing-2"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  true,
+		},
+		{
+			name:             "invalid decision",
+			paths:            []string{"/missing"},
+			input:            "x\ns\n",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Url skipped."},
+			expectedErrors:   []string{"/missing"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  true,
+		},
+		{
+			name:             "no input",
+			paths:            []string{"/missing-1", "/missing-2"},
+			input:            "",
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Could not read the decision, pause on error is disabled"},
+			expectedErrors:   []string{"/missing-1", "/missing-2"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  true,
+		},
+		{
+			name:             "decision slower than the request timeout",
+			paths:            []string{"/missing", "/a", "/b", "/c", "/d"},
+			input:            "s\n",
+			delay:            time.Second,
+			expectedPrompts:  1,
+			expectedOutput:   []string{"Url skipped."},
+			expectedErrors:   []string{"/missing"},
+			expectedExitCode: ExitCodeErrorsFound,
+			expectedResumed:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newPauseOnErrorTestServer(t, test.paths)
+
+			grawler, err := NewGrawler(Flags{
+				FlagParallel:       2,
+				FlagPauseOnError:   true,
+				FlagRequestTimeout: 0.5,
+				FlagNoStatusBar:    true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			output := &syncBuffer{}
+			grawler.SetOutput(output)
+			grawler.errorController = newErrorController(grawler, &slowReader{delay: test.delay, reader: strings.NewReader(test.input)})
+
+			if exitCode := grawler.Grawl(server.URL + "/"); exitCode != test.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d", test.expectedExitCode, exitCode)
+			}
+
+			if prompts := strings.Count(output.String(), "Pause grawling after error"); prompts != test.expectedPrompts {
+				t.Errorf("expected %d prompts, got %d", test.expectedPrompts, prompts)
+			}
+			for _, expected := range test.expectedOutput {
+				if !strings.Contains(output.String(), expected) {
+					t.Errorf("expected %q in the output", expected)
+				}
+			}
+			if resumed := strings.Contains(output.String(), "Grawling resumed."); resumed != test.expectedResumed {
+				t.Errorf("expected resumed %v, got %v", test.expectedResumed, resumed)
+			}
+			if test.expectedResumed && grawler.pause.isPaused() {
+				t.Error("expected the grawling not to be paused after all errors are decided")
+			}
+
+			errors := make([]string, 0)
+			for _, result := range *grawler.runningRequests.GetValues() {
+				if result.HasError() {
+					errors = append(errors, result.urlPath)
+				}
+			}
+			slices.Sort(errors)
+			if !slices.Equal(errors, test.expectedErrors) {
+				t.Errorf("expected errors %v, got %v", test.expectedErrors, errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package grawl
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMaxRedirects = 10
+
+	redirectStopLoop     = "redirect loop"
+	redirectStopTooMany  = "too many redirects"
+	redirectStopVisited  = "already grawled"
+	redirectStopNoFollow = "not followed"
+	redirectChainDivider = " -> "
+)
+
+var (
+	errRedirectLoop     = errors.New(redirectStopLoop)
+	errTooManyRedirects = errors.New(redirectStopTooMany)
+)
+
+// redirectHop is a response with a redirect status code
+type redirectHop struct {
+	url        string
+	statusCode int
+}
+
+type redirectHopJson struct {
+	Url        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+func isRedirectStatusCode(statusCode int) bool {
+	return statusCode >= 300 && statusCode < 400 && statusCode != http.StatusNotModified
+}
+
+// initialRequest returns the first request of a redirect chain
+func initialRequest(req *http.Request) *http.Request {
+	for req.Response != nil && req.Response.Request != nil {
+		req = req.Response.Request
+	}
+	return req
+}
+
+// formatRedirectChain formats the hops like "301 https://a -> 302 https://b -> 200 https://c"
+func formatRedirectChain(hops []redirectHop, lastPart string) string {
+	parts := make([]string, 0, len(hops)+1)
+	for _, hop := range hops {
+		parts = append(parts, strconv.Itoa(hop.statusCode)+" "+hop.url)
+	}
+	if lastPart != "" {
+		parts = append(parts, lastPart)
+	}
+	return strings.Join(parts, redirectChainDivider)
+}
+
+// parseRedirectChain parses a chain formatted by formatRedirectChain. The last part is the final response, which is
+// not a hop, or the url at which the chain stopped, e.g. "https://c (redirect loop)".
+func parseRedirectChain(chain string) (hops []redirectHop, stopUrl string, stopReason string, err error) {
+	if chain == "" {
+		return nil, "", "", nil
+	}
+
+	parts := strings.Split(chain, redirectChainDivider)
+	for _, part := range parts[:len(parts)-1] {
+		statusCode, url, found := strings.Cut(part, " ")
+		code, codeErr := strconv.Atoi(statusCode)
+		if !found || codeErr != nil {
+			return nil, "", "", fmt.Errorf("invalid redirect hop \"%s\"", part)
+		}
+		hops = append(hops, redirectHop{url: url, statusCode: code})
+	}
+
+	lastPart := parts[len(parts)-1]
+	if url, reason, found := strings.Cut(lastPart, " ("); found && strings.HasSuffix(reason, ")") {
+		stopUrl, stopReason = url, strings.TrimSuffix(reason, ")")
+	}
+	return hops, stopUrl, stopReason, nil
+}
+
+func newRedirectHopsJson(hops []redirectHop) []redirectHopJson {
+	hopsJson := make([]redirectHopJson, 0, len(hops))
+	for _, hop := range hops {
+		hopsJson = append(hopsJson, redirectHopJson{
+			Url:        hop.url,
+			StatusCode: hop.statusCode,
+		})
+	}
+	return hopsJson
+}
+
+func newRedirectHopsFromJson(hopsJson []redirectHopJson) []redirectHop {
+	hops := make([]redirectHop, 0, len(hopsJson))
+	for _, hop := range hopsJson {
+		hops = append(hops, redirectHop{
+			url:        hop.Url,
+			statusCode: hop.StatusCode,
+		})
+	}
+	return hops
+}
+
+// redirectSummary counts the redirect chains of the results
+type redirectSummary struct {
+	chains        int
+	multipleHops  int
+	longestChain  int
+	loops         int
+	notFollowed   int
+	tooManyHops   int
+	toGrawledUrls int
+}
+
+func newRedirectSummary(results []*Result) redirectSummary {
+	summary := redirectSummary{}
+	for _, result := range results {
+		if !result.HasRedirects() {
+			continue
+		}
+
+		summary.chains++
+		summary.longestChain = max(summary.longestChain, len(result.redirectChain))
+		if len(result.redirectChain) > 1 {
+			summary.multipleHops++
+		}
+
+		switch result.redirectStopReason {
+		case redirectStopLoop:
+			summary.loops++
+		case redirectStopTooMany:
+			summary.tooManyHops++
+		case redirectStopVisited:
+			summary.toGrawledUrls++
+		case redirectStopNoFollow:
+			summary.notFollowed++
+		}
+	}
+	return summary
+}
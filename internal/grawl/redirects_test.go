@@ -0,0 +1,309 @@
+package grawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newRedirectTestServer serves a home page which links redirect chains: /one redirects twice to /end, /loop-a and
+// /loop-b redirect to each other, /many redirects three times and /to-home redirects to the home page
+func newRedirectTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/one">one</a><a href="/loop-a">loop</a><a href="/many">many</a><a href="/to-home">home</a></body></html>`)
+	})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body></body></html>")
+	})
+	mux.Handle("/one", http.RedirectHandler("/two", http.StatusMovedPermanently))
+	mux.Handle("/two", http.RedirectHandler("/end", http.StatusFound))
+	mux.Handle("/loop-a", http.RedirectHandler("/loop-b", http.StatusFound))
+	mux.Handle("/loop-b", http.RedirectHandler("/loop-a", http.StatusFound))
+	mux.Handle("/many", http.RedirectHandler("/many-1", http.StatusFound))
+	mux.Handle("/many-1", http.RedirectHandler("/many-2", http.StatusFound))
+	mux.Handle("/many-2", http.RedirectHandler("/many-3", http.StatusFound))
+	mux.Handle("/many-3", http.RedirectHandler("/end", http.StatusFound))
+	mux.Handle("/to-home", http.RedirectHandler("/", http.StatusMovedPermanently))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRedirectChainFormat(t *testing.T) {
+	tests := []struct {
+		name               string
+		hops               []redirectHop
+		lastPart           string
+		expected           string
+		expectedStopUrl    string
+		expectedStopReason string
+	}{
+		{
+			name:     "no redirects",
+			expected: "",
+		},
+		{
+			name:     "single hop",
+			hops:     []redirectHop{{url: "https://a.com/", statusCode: 301}},
+			lastPart: "200 https://b.com/",
+			expected: "301 https://a.com/ -> 200 https://b.com/",
+		},
+		{
+			name:     "multiple hops",
+			hops:     []redirectHop{{url: "https://a.com/", statusCode: 301}, {url: "https://b.com/", statusCode: 307}},
+			lastPart: "404 https://c.com/",
+			expected: "301 https://a.com/ -> 307 https://b.com/ -> 404 https://c.com/",
+		},
+		{
+			name:               "stopped chain",
+			hops:               []redirectHop{{url: "https://a.com/", statusCode: 302}, {url: "https://b.com/", statusCode: 302}},
+			lastPart:           "https://a.com/ (redirect loop)",
+			expected:           "302 https://a.com/ -> 302 https://b.com/ -> https://a.com/ (redirect loop)",
+			expectedStopUrl:    "https://a.com/",
+			expectedStopReason: redirectStopLoop,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chain := formatRedirectChain(test.hops, test.lastPart)
+			if chain != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, chain)
+			}
+
+			hops, stopUrl, stopReason, err := parseRedirectChain(chain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(hops, test.hops) {
+				t.Errorf("expected hops %v, got %v", test.hops, hops)
+			}
+			if stopUrl != test.expectedStopUrl || stopReason != test.expectedStopReason {
+				t.Errorf("expected stop at %q (%q), got %q (%q)", test.expectedStopUrl, test.expectedStopReason, stopUrl, stopReason)
+			}
+		})
+	}
+}
+
+func TestParseInvalidRedirectChain(t *testing.T) {
+	for _, chain := range []string{"https://a.com/ -> 200 https://b.com/", "abc https://a.com/ -> 200 https://b.com/"} {
+		t.Run(chain, func(t *testing.T) {
+			if _, _, _, err := parseRedirectChain(chain); err == nil {
+				t.Errorf("expected an error for %q", chain)
+			}
+		})
+	}
+}
+
+func TestIsRedirectStatusCode(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		expected   bool
+	}{
+		{statusCode: 200, expected: false},
+		{statusCode: 301, expected: true},
+		{statusCode: 302, expected: true},
+		{statusCode: 304, expected: false},
+		{statusCode: 308, expected: true},
+		{statusCode: 404, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.statusCode), func(t *testing.T) {
+			if isRedirectStatusCode(test.statusCode) != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, !test.expected)
+			}
+		})
+	}
+}
+
+func TestNewRedirectSummary(t *testing.T) {
+	newRedirectResult := func(hops int, stopReason string) *Result {
+		result := &Result{redirectStopReason: stopReason}
+		for i := 0; i < hops; i++ {
+			result.AddRedirectHop(fmt.Sprintf("https://a.com/%d", i), 302)
+		}
+		return result
+	}
+
+	results := []*Result{
+		newRedirectResult(0, ""),
+		newRedirectResult(1, ""),
+		newRedirectResult(3, ""),
+		newRedirectResult(2, redirectStopLoop),
+		newRedirectResult(11, redirectStopTooMany),
+		newRedirectResult(1, redirectStopVisited),
+		newRedirectResult(1, redirectStopNoFollow),
+	}
+
+	expected := redirectSummary{
+		chains:        6,
+		multipleHops:  3,
+		longestChain:  11,
+		loops:         1,
+		notFollowed:   1,
+		tooManyHops:   1,
+		toGrawledUrls: 1,
+	}
+	if summary := newRedirectSummary(results); summary != expected {
+		t.Errorf("expected %+v, got %+v", expected, summary)
+	}
+}
+
+func TestGrawlRedirectChains(t *testing.T) {
+	server := newRedirectTestServer(t)
+
+	grawler, err := NewGrawler(Flags{
+		FlagParallel:       1,
+		FlagRequestTimeout: 5,
+		FlagMaxRedirects:   2,
+		FlagNoStatusBar:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grawler.SetOutput(io.Discard)
+
+	if err = grawler.Crawl(context.Background(), server.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+
+	results := map[string]*Result{}
+	for _, result := range *grawler.runningRequests.GetValues() {
+		results[result.initialRequestUrl] = result
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedUrl   string
+		expectedChain string
+		expectedError bool
+	}{
+		{
+			name:          "multiple hops",
+			path:          "/one",
+			expectedUrl:   "/end",
+			expectedChain: "301 {server}/one -> 302 {server}/two -> 200 {server}/end",
+		},
+		{
+			name:          "loop",
+			path:          "/loop-a",
+			expectedUrl:   "/loop-a",
+			expectedChain: "302 {server}/loop-a -> 302 {server}/loop-b -> {server}/loop-a (redirect loop)",
+			expectedError: true,
+		},
+		{
+			name:          "too many hops",
+			path:          "/many",
+			expectedUrl:   "/many",
+			expectedChain: "302 {server}/many -> 302 {server}/many-1 -> 302 {server}/many-2 -> {server}/many-3 (too many redirects)",
+			expectedError: true,
+		},
+		{
+			name:          "to a grawled url",
+			path:          "/to-home",
+			expectedUrl:   "/to-home",
+			expectedChain: "301 {server}/to-home -> {server}/ (already grawled)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := results[server.URL+test.path]
+			if !ok {
+				t.Fatalf("expected a result of %s", test.path)
+			}
+			if result.url != server.URL+test.expectedUrl {
+				t.Errorf("expected url %s, got %s", server.URL+test.expectedUrl, result.url)
+			}
+			if chain := strings.ReplaceAll(test.expectedChain, "{server}", server.URL); result.GetRedirectChain() != chain {
+				t.Errorf("expected %q, got %q", chain, result.GetRedirectChain())
+			}
+			if result.HasError() != test.expectedError {
+				t.Errorf("expected error %v, got %v", test.expectedError, result.HasError())
+			}
+		})
+	}
+}
+
+func TestGrawlNoFollowRedirects(t *testing.T) {
+	server := newRedirectTestServer(t)
+
+	tests := []struct {
+		name                 string
+		visitRedirectTargets bool
+		expectedUrls         []string
+	}{
+		{
+			name:         "redirect targets not visited",
+			expectedUrls: []string{"/", "/loop-a", "/many", "/one", "/to-home"},
+		},
+		{
+			name:                 "redirect targets visited",
+			visitRedirectTargets: true,
+			expectedUrls: []string{
+				"/", "/end", "/loop-a", "/loop-b", "/many", "/many-1", "/many-2", "/many-3", "/one", "/to-home", "/two",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grawler, err := NewGrawler(Flags{
+				FlagParallel:             1,
+				FlagRequestTimeout:       5,
+				FlagNoFollowRedirects:    true,
+				FlagVisitRedirectTargets: test.visitRedirectTargets,
+				FlagNoStatusBar:          true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			grawler.SetOutput(io.Discard)
+
+			if err = grawler.Crawl(context.Background(), server.URL+"/"); err != nil {
+				t.Fatal(err)
+			}
+
+			urls := make([]string, 0)
+			var redirect *Result
+			for _, result := range *grawler.runningRequests.GetValues() {
+				urls = append(urls, strings.TrimPrefix(result.url, server.URL))
+				if result.initialRequestUrl != result.url {
+					t.Errorf("expected %s not to be redirected, got %s", result.initialRequestUrl, result.url)
+				}
+				if result.url == server.URL+"/one" {
+					redirect = result
+				}
+			}
+			if !slices.Equal(urls, test.expectedUrls) {
+				t.Errorf("expected %v, got %v", test.expectedUrls, urls)
+			}
+
+			if redirect == nil {
+				t.Fatal("expected a result of /one")
+			}
+			if redirect.statusCode != http.StatusMovedPermanently || redirect.HasError() {
+				t.Errorf("expected a 301 without an error, got %d and %v", redirect.statusCode, redirect.HasError())
+			}
+			if redirect.GetLocation() != server.URL+"/two" {
+				t.Errorf("expected the location %s, got %s", server.URL+"/two", redirect.GetLocation())
+			}
+			if chain := "301 " + server.URL + "/one -> " + server.URL + "/two (not followed)"; redirect.GetRedirectChain() != chain {
+				t.Errorf("expected %q, got %q", chain, redirect.GetRedirectChain())
+			}
+		})
+	}
+}
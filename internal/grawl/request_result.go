@@ -5,19 +5,35 @@ import (
 	"github.com/gocolly/colly/v2"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Result struct {
-	id                uint32
-	Index             uint32
-	initialRequestUrl string
-	url               string
-	urlHost           string
-	urlPath           string
-	urlParmeters      string
-	urlFragment       string
-	urlRedirectedFrom string
+	id                 uint32
+	Index              uint32
+	initialRequestUrl  string
+	url                string
+	urlHost            string
+	urlPath            string
+	urlParmeters       string
+	urlFragment        string
+	urlRedirectedFrom  string
+	redirectChain      []redirectHop
+	redirectStopUrl    string
+	redirectStopReason string
+	location           string
+	lastModified       time.Time
+	noindex            bool
+	canonicalUrl       string
+	retriedErrors      []string
+	timing             requestTiming
+	cacheHeaders       []string
+	cacheStatus        string
+	warmed             bool
+	warmStatusCode     int
+	warmCacheStatus    string
+	warmDuration       time.Duration
 	//duration            time.Duration
 	requestAt           time.Time
 	responseAt          time.Time
@@ -31,6 +47,7 @@ type Result struct {
 	httpErrorCodeRanges *responseCodeRanges
 	requestCount        uint32
 	updatedAtResponse   bool
+	done                bool
 }
 
 func NewResult(id uint32, url string, foundOnUrl string, httpErrorRanges *responseCodeRanges) *Result {
@@ -62,6 +79,89 @@ func (r *Result) UpdateOnRoundTripEnd(responseTime time.Time) {
 	r.responseAt = responseTime
 }
 
+// AddTiming adds the phases of a round trip, the phases of a redirect chain are added up
+func (r *Result) AddTiming(timing requestTiming) {
+	r.timing.add(timing)
+}
+
+// AddRedirectHop adds a response with a redirect status code to the redirect chain
+func (r *Result) AddRedirectHop(url string, statusCode int) {
+	r.redirectChain = append(r.redirectChain, redirectHop{
+		url:        url,
+		statusCode: statusCode,
+	})
+}
+
+// StopRedirects records the url at which the redirect chain has not been followed any further
+func (r *Result) StopRedirects(url string, reason string) {
+	r.redirectStopUrl = url
+	r.redirectStopReason = reason
+}
+
+// GetUrl returns the url of the response, it is the last url of the redirects
+func (r *Result) GetUrl() string {
+	return r.url
+}
+
+// GetInitialUrl returns the requested url before any redirects
+func (r *Result) GetInitialUrl() string {
+	return r.initialRequestUrl
+}
+
+func (r *Result) GetFoundOnUrl() string {
+	return r.foundOnUrl
+}
+
+// GetStatusCode returns the status code of the response, 0 if the request failed without a response
+func (r *Result) GetStatusCode() int {
+	return r.statusCode
+}
+
+func (r *Result) GetContentType() string {
+	return r.contentType
+}
+
+func (r *Result) GetDepth() int {
+	return r.depth
+}
+
+// GetError returns the error of a request without a response, errors of status codes are checked with HasError
+func (r *Result) GetError() error {
+	return r.error
+}
+
+// GetLocation returns the absolute url of the location header of a redirect response which has not been followed
+func (r *Result) GetLocation() string {
+	return r.location
+}
+
+// GetAttempts returns the number of requests of the url, failed requests are retried with --retries
+func (r *Result) GetAttempts() int {
+	return len(r.retriedErrors) + 1
+}
+
+// GetRetriedErrors returns the errors of the failed attempts before the last one
+func (r *Result) GetRetriedErrors() string {
+	return strings.Join(r.retriedErrors, retriedErrorsDivider)
+}
+
+func (r *Result) HasRedirects() bool {
+	return len(r.redirectChain) > 0
+}
+
+// GetRedirectChain returns all hops of the redirects, e.g. "301 https://a -> 302 https://b -> 200 https://c"
+func (r *Result) GetRedirectChain() string {
+	if !r.HasRedirects() {
+		return ""
+	}
+
+	if r.redirectStopUrl != "" {
+		return formatRedirectChain(r.redirectChain, fmt.Sprintf("%s (%s)", r.redirectStopUrl, r.redirectStopReason))
+	}
+
+	return formatRedirectChain(r.redirectChain, fmt.Sprintf("%d %s", r.statusCode, r.url))
+}
+
 func (r *Result) UpdateOnResponse(
 	response *colly.Response,
 	index uint32,
@@ -79,11 +179,33 @@ func (r *Result) UpdateOnResponse(
 		r.url = requestUrl
 	}
 
-	r.status = http.StatusText(response.StatusCode)
-	r.statusShort = StatusAbbreviation(response.StatusCode)
+	statusCode := response.StatusCode
+
+	if isRedirectStatusCode(statusCode) && response.Headers != nil && response.Headers.Get("Location") != "" {
+		r.location = response.Request.AbsoluteURL(response.Headers.Get("Location"))
+
+		//
+		// A redirect response which is not followed, see --no-follow-redirects
+		//
+		if r.HasRedirects() && r.redirectStopUrl == "" {
+			r.StopRedirects(r.location, redirectStopNoFollow)
+		}
+	}
+
+	//
+	// A redirect chain that has not been followed to the end gets the status code of the last redirect
+	//
+	if statusCode == 0 && r.redirectStopUrl != "" && r.HasRedirects() {
+		statusCode = r.redirectChain[len(r.redirectChain)-1].statusCode
+	}
+
+	r.status = http.StatusText(statusCode)
+	r.statusShort = StatusAbbreviation(statusCode)
 
-	if err != nil && response.StatusCode == 0 {
-		r.status = "Skipped"
+	if err != nil && statusCode == 0 {
+		r.status = "Error"
+	} else if r.redirectStopUrl != "" {
+		r.status += " (" + r.redirectStopReason + ")"
 	} else if r.IsRedirected() {
 		r.status += " (Redirected)"
 	}
@@ -95,12 +217,16 @@ func (r *Result) UpdateOnResponse(
 	r.urlHost = response.Request.URL.Host
 	r.urlParmeters = response.Request.URL.RawQuery
 	r.urlFragment = response.Request.URL.RawFragment
-	r.statusCode = response.StatusCode
+	r.statusCode = statusCode
 	r.depth = response.Request.Depth
 
 	//fmt.Println("CT", r.contentType, " - ", response.Headers.Get("Content-Type"))
 
-	r.contentType = response.Headers.Get("Content-Type")
+	if response.Headers != nil {
+		r.contentType = response.Headers.Get("Content-Type")
+		r.lastModified, _ = http.ParseTime(response.Headers.Get("Last-Modified"))
+		r.noindex = isNoindex(response.Headers.Get("X-Robots-Tag"))
+	}
 	r.updatedAtResponse = true
 
 	if err != nil {
@@ -122,20 +248,52 @@ func (r *Result) GetPrintRow() string {
 	row += " - "
 	row += r.url
 
-	if r.IsRedirected() {
-		row += " - Redirected from: " + r.urlRedirectedFrom
+	if r.HasRedirects() {
+		row += " - Redirects: " + r.GetRedirectChain()
 	}
 
 	if r.HasError() {
 		row += " - Found on: " + r.foundOnUrl
 	}
 
+	if len(r.retriedErrors) > 0 {
+		row += fmt.Sprintf(" - Attempts: %d", r.GetAttempts())
+	}
+
+	if r.error != nil {
+		row += " - Error: " + r.error.Error()
+	}
+
 	return row
 
 }
 
 func (r *Result) HasError() bool {
-	return r.error != nil || r.httpErrorCodeRanges.IsError(r.statusCode)
+	if r.error != nil || r.httpErrorCodeRanges.IsError(r.statusCode) {
+		return true
+	}
+
+	for _, hop := range r.redirectChain {
+		if r.httpErrorCodeRanges.IsError(hop.statusCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorStatusCode returns the status code which makes the result an error, it may be the status code of a
+// redirect. Errors without a response have 0.
+func (r *Result) errorStatusCode() int {
+	if r.httpErrorCodeRanges.IsError(r.statusCode) || r.error != nil {
+		return r.statusCode
+	}
+
+	for _, hop := range r.redirectChain {
+		if r.httpErrorCodeRanges.IsError(hop.statusCode) {
+			return hop.statusCode
+		}
+	}
+	return r.statusCode
 }
 
 func (r *Result) GetDuration() time.Duration {
@@ -7,11 +7,23 @@ import (
 	"strings"
 )
 
+const (
+	minResponseCode = 100
+	maxResponseCode = 599
+)
+
 type responseCodeRange struct {
 	minCode int
 	maxCode int
 }
 
+func (r responseCodeRange) String() string {
+	if r.minCode == r.maxCode {
+		return strconv.Itoa(r.minCode)
+	}
+	return fmt.Sprintf("%d-%d", r.minCode, r.maxCode)
+}
+
 type responseCodeRanges struct {
 	ranges []responseCodeRange
 }
@@ -25,19 +37,35 @@ func (r *responseCodeRanges) IsError(responseCode int) bool {
 	return false
 }
 
+func (r *responseCodeRanges) String() string {
+	values := make([]string, 0, len(r.ranges))
+	for _, codeRange := range r.ranges {
+		values = append(values, codeRange.String())
+	}
+	return strings.Join(values, ", ")
+}
+
 func newResponseCodeRanges(responseCodeFlags []string) (*responseCodeRanges, error) {
 	var ranges = responseCodeRanges{}
 
-	regexRange := regexp.MustCompile(`(\d+)\s*-\s*(\d+)`)
+	regexRange := regexp.MustCompile(`^(\d+)\s*-\s*(\d+)$`)
 
 	for _, responseCodeFlag := range responseCodeFlags {
 		responseCodeFlag = strings.TrimSpace(responseCodeFlag)
+		if responseCodeFlag == "" {
+			continue
+		}
+
 		matches := regexRange.FindStringSubmatch(responseCodeFlag)
 
 		if len(matches) < 3 {
 			val, err := strconv.Atoi(responseCodeFlag)
 			if err != nil {
-				return nil, fmt.Errorf("could not parse http-error-codes: %s", responseCodeFlag)
+				return nil, fmt.Errorf("could not parse response error code \"%s\": use a single code like \"404\" or a range like \"400-599\"", responseCodeFlag)
+			}
+
+			if err = checkResponseCode(val, responseCodeFlag); err != nil {
+				return nil, err
 			}
 
 			resSingle := responseCodeRange{
@@ -49,16 +77,28 @@ func newResponseCodeRanges(responseCodeFlags []string) (*responseCodeRanges, err
 			continue
 		}
 
-		minVal, err := strconv.Atoi(strings.TrimSpace(matches[1]))
+		minVal, err := strconv.Atoi(matches[1])
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("could not parse lower bound of response error code range \"%s\": %v", responseCodeFlag, err)
 		}
 
-		maxVal, err := strconv.Atoi(strings.TrimSpace(matches[2]))
+		maxVal, err := strconv.Atoi(matches[2])
 		if err != nil {
+			return nil, fmt.Errorf("could not parse upper bound of response error code range \"%s\": %v", responseCodeFlag, err)
+		}
+
+		if err = checkResponseCode(minVal, responseCodeFlag); err != nil {
+			return nil, err
+		}
+
+		if err = checkResponseCode(maxVal, responseCodeFlag); err != nil {
 			return nil, err
 		}
 
+		if minVal > maxVal {
+			return nil, fmt.Errorf("invalid response error code range \"%s\": lower bound %d is greater than upper bound %d, did you mean \"%d-%d\"?", responseCodeFlag, minVal, maxVal, maxVal, minVal)
+		}
+
 		res := responseCodeRange{
 			minCode: minVal,
 			maxCode: maxVal,
@@ -73,3 +113,10 @@ func newResponseCodeRanges(responseCodeFlags []string) (*responseCodeRanges, err
 
 	return &ranges, nil
 }
+
+func checkResponseCode(code int, responseCodeFlag string) error {
+	if code < minResponseCode || code > maxResponseCode {
+		return fmt.Errorf("invalid response error code \"%s\": %d is not a http status code between %d and %d", responseCodeFlag, code, minResponseCode, maxResponseCode)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package grawl
+
+import (
+	"testing"
+)
+
+func TestNewResponseCodeRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    []string
+		expected string
+		invalid  bool
+	}{
+		{
+			name:     "single codes and ranges",
+			flags:    []string{"404", " 500 - 599 "},
+			expected: "404, 500-599",
+		},
+		{
+			name:     "default for empty flags",
+			flags:    []string{"", " "},
+			expected: "400-599",
+		},
+		{
+			name:     "bounds",
+			flags:    []string{"100-599"},
+			expected: "100-599",
+		},
+		{
+			name:    "below the lower bound",
+			flags:   []string{"99"},
+			invalid: true,
+		},
+		{
+			name:    "above the upper bound",
+			flags:   []string{"500-600"},
+			invalid: true,
+		},
+		{
+			name:    "inverted range",
+			flags:   []string{"599-500"},
+			invalid: true,
+		},
+		{
+			name:    "text before the range",
+			flags:   []string{"x400-499"},
+			invalid: true,
+		},
+		{
+			name:    "text after the range",
+			flags:   []string{"400-499x"},
+			invalid: true,
+		},
+		{
+			name:    "several ranges in one flag",
+			flags:   []string{"400-404-500"},
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranges, err := newResponseCodeRanges(test.flags)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %v", ranges)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ranges.String() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, ranges)
+			}
+		})
+	}
+}
+
+func TestResponseCodeRangesIsError(t *testing.T) {
+	ranges, err := newResponseCodeRanges([]string{"404", "500-503"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		code     int
+		expected bool
+	}{
+		{code: 200, expected: false},
+		{code: 403, expected: false},
+		{code: 404, expected: true},
+		{code: 499, expected: false},
+		{code: 500, expected: true},
+		{code: 503, expected: true},
+		{code: 504, expected: false},
+	}
+
+	for _, test := range tests {
+		if isError := ranges.IsError(test.code); isError != test.expected {
+			t.Errorf("expected %v for %d, got %v", test.expected, test.code, isError)
+		}
+	}
+}
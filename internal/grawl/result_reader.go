@@ -0,0 +1,196 @@
+package grawl
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readResults reads the results of an output file written by the FileWriter or the JsonLinesWriter
+func readResults(filePath string, httpErrorRanges *responseCodeRanges) ([]*Result, error) {
+	format, err := getOutputFormat(filePath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if format == OutputFormatJsonl {
+		return readJsonLinesResults(file, httpErrorRanges)
+	}
+	return readCsvResults(file, httpErrorRanges)
+}
+
+func readJsonLinesResults(reader io.Reader, httpErrorRanges *responseCodeRanges) ([]*Result, error) {
+	results := make([]*Result, 0)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var j resultJson
+		if err := json.Unmarshal(scanner.Bytes(), &j); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		result, err := newResultFromJson(j, httpErrorRanges)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, scanner.Err()
+}
+
+// readCsvResults reads the columns by their header, so files of older versions with fewer columns can be read, too
+func readCsvResults(reader io.Reader, httpErrorRanges *responseCodeRanges) ([]*Result, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = ';'
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read csv header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	if _, ok := columns["URL"]; !ok {
+		return nil, errors.New("csv file has no \"URL\" column")
+	}
+
+	results := make([]*Result, 0)
+	for line := 2; ; line++ {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return row[i]
+		}
+
+		result, err := newResultFromCsv(value, httpErrorRanges)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func newResultFromCsv(value func(column string) string, httpErrorRanges *responseCodeRanges) (*Result, error) {
+	initialRequestUrl := value("URL")
+	if value("Redirected from") != "" {
+		initialRequestUrl = value("Redirected from")
+	}
+
+	result := NewResult(0, initialRequestUrl, value("Found on URL"), httpErrorRanges)
+	result.url = value("URL")
+	result.urlRedirectedFrom = value("Redirected from")
+	result.status = value("Status")
+	result.contentType = value("Content type")
+	result.urlHost = value("Host")
+	result.urlPath = value("Path")
+	result.urlParmeters = value("Parameters")
+	result.urlFragment = value("Fragment")
+	result.location = value("Location")
+	result.cacheStatus = value("Cache status")
+	if value("Cache headers") != "" {
+		result.cacheHeaders = strings.Split(value("Cache headers"), cacheHeadersDivider)
+	}
+
+	// The redirect chain is missing in files of older versions
+	var err error
+	result.redirectChain, result.redirectStopUrl, result.redirectStopReason, err = parseRedirectChain(value("Redirect chain"))
+	if err != nil {
+		return nil, err
+	}
+
+	if value("Retried errors") != "" {
+		result.retriedErrors = strings.Split(value("Retried errors"), retriedErrorsDivider)
+	}
+	result.updatedAtResponse = true
+
+	if result.statusCode, err = strconv.Atoi(value("Status code")); err != nil {
+		return nil, fmt.Errorf("invalid status code: %v", err)
+	}
+	result.statusShort = StatusAbbreviation(result.statusCode)
+
+	if value("Depth") != "" {
+		if result.depth, err = strconv.Atoi(value("Depth")); err != nil {
+			return nil, fmt.Errorf("invalid depth: %v", err)
+		}
+	}
+
+	if value("Response time") != "" {
+		if result.responseAt, err = time.ParseInLocation(DateFormat, value("Response time"), time.Local); err != nil {
+			return nil, fmt.Errorf("invalid response time: %v", err)
+		}
+	}
+
+	durationMs, err := strconv.ParseInt(value("Duration (ms)"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %v", err)
+	}
+	result.requestAt = result.responseAt.Add(-time.Duration(durationMs) * time.Millisecond)
+
+	if value("Warm status code") != "" {
+		result.warmed = true
+		if result.warmStatusCode, err = strconv.Atoi(value("Warm status code")); err != nil {
+			return nil, fmt.Errorf("invalid warm status code: %v", err)
+		}
+		result.warmCacheStatus = value("Warm cache status")
+		warmMs, err := strconv.ParseInt(value("Warm duration (ms)"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid warm duration: %v", err)
+		}
+		result.warmDuration = time.Duration(warmMs) * time.Millisecond
+	}
+
+	// The timing columns are missing in files of older versions
+	timings := []*time.Duration{&result.timing.dns, &result.timing.connect, &result.timing.tls, &result.timing.ttfb, &result.timing.download}
+	for i, column := range []string{"DNS (ms)", "Connect (ms)", "TLS (ms)", "TTFB (ms)", "Download (ms)"} {
+		if value(column) == "" {
+			continue
+		}
+		ms, err := strconv.ParseInt(value(column), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", column, err)
+		}
+		*timings[i] = time.Duration(ms) * time.Millisecond
+	}
+	result.timing.newConnection = result.timing.hasConnectionPhases()
+
+	if value("Info / error") != "" {
+		result.error = errors.New(value("Info / error"))
+	}
+
+	return result, nil
+}
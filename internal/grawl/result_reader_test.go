@@ -0,0 +1,117 @@
+package grawl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCsvResults(t *testing.T) {
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		csv                string
+		expectedUrl        string
+		expectedInitialUrl string
+		expectedStatusCode int
+		expectedDuration   time.Duration
+		expectedChain      string
+		expectedError      bool
+		invalid            bool
+	}{
+		{
+			name:               "columns of older versions",
+			csv:                "Response time;URL;Status code;Duration (ms)\n2024-01-01 10:00:00.000;https://example.com/a;200;120\n",
+			expectedUrl:        "https://example.com/a",
+			expectedInitialUrl: "https://example.com/a",
+			expectedStatusCode: 200,
+			expectedDuration:   120 * time.Millisecond,
+		},
+		{
+			name:               "columns in another order",
+			csv:                "Duration (ms);Response time;URL;Status code\n80;2024-01-01 10:00:00.000;https://example.com/a;404\n",
+			expectedUrl:        "https://example.com/a",
+			expectedInitialUrl: "https://example.com/a",
+			expectedStatusCode: 404,
+			expectedDuration:   80 * time.Millisecond,
+			expectedError:      true,
+		},
+		{
+			name: "redirect chain",
+			csv: "Response time;URL;Redirected from;Status code;Duration (ms);Redirect chain\n" +
+				"2024-01-01 10:00:00.000;https://example.com/c;https://example.com/a;200;50;301 https://example.com/a -> 302 https://example.com/b -> 200 https://example.com/c\n",
+			expectedUrl:        "https://example.com/c",
+			expectedInitialUrl: "https://example.com/a",
+			expectedStatusCode: 200,
+			expectedDuration:   50 * time.Millisecond,
+			expectedChain:      "301 https://example.com/a -> 302 https://example.com/b -> 200 https://example.com/c",
+		},
+		{
+			name:               "redirect chain with an error hop",
+			csv:                "Response time;URL;Status code;Duration (ms);Redirect chain\n2024-01-01 10:00:00.000;https://example.com/b;200;50;404 https://example.com/a -> 200 https://example.com/b\n",
+			expectedUrl:        "https://example.com/b",
+			expectedInitialUrl: "https://example.com/b",
+			expectedStatusCode: 200,
+			expectedDuration:   50 * time.Millisecond,
+			expectedChain:      "404 https://example.com/a -> 200 https://example.com/b",
+			expectedError:      true,
+		},
+		{
+			name:    "no url column",
+			csv:     "Status code;Duration (ms)\n200;120\n",
+			invalid: true,
+		},
+		{
+			name:    "invalid status code",
+			csv:     "URL;Status code;Duration (ms)\nhttps://example.com/a;ok;120\n",
+			invalid: true,
+		},
+		{
+			name:    "invalid redirect chain",
+			csv:     "URL;Status code;Duration (ms);Redirect chain\nhttps://example.com/b;200;50;moved https://example.com/a -> 200 https://example.com/b\n",
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := readCsvResults(strings.NewReader(test.csv), errorCodeRanges)
+			if test.invalid {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+
+			result := results[0]
+			if result.url != test.expectedUrl {
+				t.Errorf("expected url %s, got %s", test.expectedUrl, result.url)
+			}
+			if result.initialRequestUrl != test.expectedInitialUrl {
+				t.Errorf("expected initial url %s, got %s", test.expectedInitialUrl, result.initialRequestUrl)
+			}
+			if result.statusCode != test.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", test.expectedStatusCode, result.statusCode)
+			}
+			if result.GetDuration() != test.expectedDuration {
+				t.Errorf("expected duration %s, got %s", test.expectedDuration, result.GetDuration())
+			}
+			if result.GetRedirectChain() != test.expectedChain {
+				t.Errorf("expected redirect chain %q, got %q", test.expectedChain, result.GetRedirectChain())
+			}
+			if result.HasError() != test.expectedError {
+				t.Errorf("expected error %v, got %v", test.expectedError, result.HasError())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package grawl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	OutputFormatCsv   = "csv"
+	OutputFormatJsonl = "jsonl"
+)
+
+// ResultWriter writes the result of each request to the output file
+type ResultWriter interface {
+	// InitFile creates the file and prints its name to out. When resuming a grawling an existing file is continued.
+	InitFile(out io.Writer, resume bool) error
+	WriteResultLine(r *Result) error
+	// Close flushes all results and closes the file
+	Close() error
+}
+
+// NewResultWriter creates the writer for the output format. If no format is given it is detected by the file extension.
+func NewResultWriter(filePath string, outputFormat string) (ResultWriter, error) {
+	format, err := getOutputFormat(filePath, outputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case OutputFormatJsonl:
+		return NewJsonLinesWriter(filePath), nil
+	default:
+		return NewFileWriter(filePath), nil
+	}
+}
+
+func getOutputFormat(filePath string, outputFormat string) (string, error) {
+	outputFormat = strings.ToLower(strings.TrimSpace(outputFormat))
+
+	switch outputFormat {
+	case OutputFormatCsv, OutputFormatJsonl:
+		return outputFormat, nil
+	case "":
+		break
+	default:
+		return "", fmt.Errorf("unknown output format \"%s\": use \"%s\" or \"%s\"", outputFormat, OutputFormatCsv, OutputFormatJsonl)
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".jsonl", ".ndjson":
+		return OutputFormatJsonl, nil
+	default:
+		return OutputFormatCsv, nil
+	}
+}
+
+// openResultFile creates the file or opens an existing file for appending when resuming a grawling
+func openResultFile(out io.Writer, filePath string, resume bool) (file *os.File, continued bool, err error) {
+	if resume {
+		if _, err = os.Stat(filePath); err == nil {
+			fmt.Fprintf(out, "Continuing file \"%s\".\n", filePath)
+			file, err = os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0644)
+			return file, true, err
+		}
+	}
+
+	fmt.Fprintf(out, "Saving file \"%s\".\n", filePath)
+
+	file, err = os.Create(filePath)
+	return file, false, err
+}
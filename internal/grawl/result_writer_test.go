@@ -0,0 +1,106 @@
+package grawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGrawlOutputFileErrors(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is needed for write errors")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/page">page</a></body></html>`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		outputFilename string
+		outputFormat   string
+		expectedError  string
+	}{
+		{
+			name:           "missing directory",
+			outputFilename: filepath.Join(t.TempDir(), "missing", "results.csv"),
+			expectedError:  "Error creating output file",
+		},
+		{
+			name:           "csv header",
+			outputFilename: "/dev/full",
+			outputFormat:   OutputFormatCsv,
+			expectedError:  "Error creating output file",
+		},
+		{
+			name:           "jsonl result",
+			outputFilename: "/dev/full",
+			outputFormat:   OutputFormatJsonl,
+			expectedError:  "Error writing output file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grawler, err := NewGrawler(Flags{
+				FlagParallel:       1,
+				FlagRequestTimeout: 5,
+				FlagOutputFilename: test.outputFilename,
+				FlagOutputFormat:   test.outputFormat,
+				FlagNoStatusBar:    true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			grawler.SetOutput(io.Discard)
+
+			err = grawler.Crawl(context.Background(), server.URL+"/")
+			if err == nil || !strings.HasPrefix(err.Error(), test.expectedError) {
+				t.Errorf("expected %q, got %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGetOutputFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		filePath     string
+		outputFormat string
+		expected     string
+		invalid      bool
+	}{
+		{name: "csv extension", filePath: "results.csv", expected: OutputFormatCsv},
+		{name: "jsonl extension", filePath: "results.JSONL", expected: OutputFormatJsonl},
+		{name: "ndjson extension", filePath: "results.ndjson", expected: OutputFormatJsonl},
+		{name: "unknown extension", filePath: "results.txt", expected: OutputFormatCsv},
+		{name: "format before extension", filePath: "results.csv", outputFormat: " JSONL ", expected: OutputFormatJsonl},
+		{name: "unknown format", filePath: "results.csv", outputFormat: "xml", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			format, err := getOutputFormat(test.filePath, test.outputFormat)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %v", format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, format)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package grawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	RetryOnTimeout    = "timeout"
+	RetryOnConnection = "connection"
+
+	// retryMaxDelay limits the exponential backoff and the wait for a Retry-After header
+	retryMaxDelay = 30 * time.Second
+
+	retriedErrorsDivider = " | "
+)
+
+var DefaultRetryOn = []string{RetryOnTimeout, RetryOnConnection, "502-504"}
+
+// retryPolicy decides which failed requests are retried and how long to wait before the next attempt
+type retryPolicy struct {
+	retries      int
+	baseDelay    time.Duration
+	onTimeout    bool
+	onConnection bool
+	statusCodes  *responseCodeRanges
+}
+
+func newRetryPolicy(retries int, baseDelayMs int64, retryOn []string) (*retryPolicy, error) {
+	if retries < 0 {
+		return nil, fmt.Errorf("invalid number of retries %d", retries)
+	}
+
+	policy := &retryPolicy{
+		retries:   retries,
+		baseDelay: time.Duration(max(0, baseDelayMs)) * time.Millisecond,
+	}
+
+	statusCodes := make([]string, 0, len(retryOn))
+	for _, value := range retryOn {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case RetryOnTimeout:
+			policy.onTimeout = true
+		case RetryOnConnection:
+			policy.onConnection = true
+		case "":
+		default:
+			statusCodes = append(statusCodes, value)
+		}
+	}
+
+	// Without status codes no response is retried, the ranges would default to all error codes
+	if len(statusCodes) == 0 {
+		return policy, nil
+	}
+
+	var err error
+	if policy.statusCodes, err = newResponseCodeRanges(statusCodes); err != nil {
+		return nil, fmt.Errorf("invalid retry condition: %v", err)
+	}
+
+	return policy, nil
+}
+
+// isRetryable checks if the failure is transient, e.g. a timeout or a 503 response
+func (p *retryPolicy) isRetryable(r *colly.Response, err error) bool {
+	if r.StatusCode > 0 {
+		return p.statusCodes != nil && p.statusCodes.IsError(r.StatusCode)
+	}
+
+	if err == nil {
+		return false
+	}
+
+	if p.onTimeout && isTimeoutError(err) {
+		return true
+	}
+
+	return p.onConnection && isConnectionError(err)
+}
+
+// backoff returns the exponential delay with jitter before the attempt. A longer Retry-After of the
+// response is respected.
+func (p *retryPolicy) backoff(attempt int, r *colly.Response) time.Duration {
+	delay := min(p.baseDelay<<max(0, attempt-2), retryMaxDelay)
+	if delay > 0 {
+		// Half of the delay is random, so parallel requests do not retry all at once
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+
+	if r.Headers != nil {
+		retryAfter := min(parseRetryAfter(r.Headers.Get("Retry-After"), time.Now()), retryMaxDelay)
+		delay = max(delay, retryAfter)
+	}
+	return delay
+}
+
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+func isConnectionError(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		// The transport returns io.EOF if the server closes the connection before sending a response
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// canRetry checks if the failure is retried and the request has attempts left
+func (g *Grawler) canRetry(r *colly.Response, err error) bool {
+	if g.retryPolicy.retries == 0 || !g.retryPolicy.isRetryable(r, err) {
+		return false
+	}
+
+	reqResult, ok := g.runningRequests.Load(r.Request.ID)
+	return ok && len(reqResult.retriedErrors) < g.retryPolicy.retries
+}
+
+// retryRequest requests the url of a failed request again, the new request waits for the backoff before it is sent.
+// The failed attempt is not reported, the errors of all attempts are added to the result of the next attempt.
+func (g *Grawler) retryRequest(r *colly.Response, err error) bool {
+	if !g.canRetry(r, err) {
+		return false
+	}
+
+	reqResult, ok := g.runningRequests.Load(r.Request.ID)
+	if !ok {
+		return false
+	}
+
+	// Redirects are retried from the start of the redirect chain
+	initialUrl, parseErr := url.Parse(reqResult.initialRequestUrl)
+	if parseErr != nil {
+		return false
+	}
+
+	attemptError := attemptError(r.StatusCode, err)
+	retriedErrors := append(reqResult.retriedErrors, attemptError)
+
+	attempt := len(retriedErrors) + 1
+	delay := g.retryPolicy.backoff(attempt, r)
+	fmt.Fprintf(g.out(), "Retrying %s in %s (attempt %d of %d): %s\n", reqResult.initialRequestUrl, delay.Round(time.Millisecond), attempt, g.retryPolicy.retries+1, attemptError)
+
+	// The url stays queued, so it is saved with the state if the grawling stops during the backoff
+	g.runningRequests.Delete(r.Request.ID)
+	g.requestCount.Add(^uint32(0))
+
+	g.retriedErrors.Store(reqResult.initialRequestUrl, retriedErrors)
+	g.retryBackoffs.Store(reqResult.initialRequestUrl, time.Now().Add(delay))
+	r.Request.URL = initialUrl
+	if retryErr := r.Request.Retry(); retryErr != nil {
+		fmt.Fprintf(g.out(), "Could not retry %s: %v\n", reqResult.initialRequestUrl, retryErr)
+		g.retriedErrors.Delete(reqResult.initialRequestUrl)
+		g.retryBackoffs.Delete(reqResult.initialRequestUrl)
+		g.runningRequests.Store(r.Request.ID, reqResult, reqResult.initialRequestUrl)
+		g.requestCount.Add(1)
+		return false
+	}
+	return true
+}
+
+// waitRetryBackoff delays the request of a retried url until its backoff has passed. The callback of the failed
+// attempt has returned already, so only the goroutine of the new request waits. It returns false if the grawling
+// stopped meanwhile.
+func (g *Grawler) waitRetryBackoff(requestUrl string) bool {
+	notBefore, ok := g.retryBackoffs.LoadAndDelete(requestUrl)
+	if !ok {
+		return true
+	}
+
+	select {
+	case <-time.After(time.Until(notBefore.(time.Time))):
+		return true
+	case <-g.stopped:
+		return false
+	}
+}
+
+// retryFailedUrl requests a failed url once more on demand, e.g. from the tui. The failed result is replaced by
+// the result of the new attempt.
+func (g *Grawler) retryFailedUrl(failedUrl string) error {
+	if g.isStopping() {
+		return errGrawlingStopped
+	}
+
+	value, ok := g.failedRequests.LoadAndDelete(failedUrl)
+	if !ok {
+		return fmt.Errorf("no failed request of %s in this grawling", failedUrl)
+	}
+	depth := value.(int)
+
+	reqResult, ok := g.runningRequests.LoadByUrl(failedUrl)
+	if !ok {
+		return fmt.Errorf("no result found for %s", failedUrl)
+	}
+
+	request, err := newCollyRequest(g.collector, failedUrl, depth)
+	if err != nil {
+		g.failedRequests.Store(failedUrl, depth)
+		return err
+	}
+
+	checkOnly := g.runningRequests.IsCheckOnlyUrl(failedUrl)
+	g.runningRequests.Delete(reqResult.id)
+	g.runningRequests.AddQueuedUrl(failedUrl, depth, reqResult.foundOnUrl, checkOnly)
+	g.requestCount.Add(^uint32(0))
+	g.responseCount.Add(^uint32(0))
+	g.forgetStatusBar(reqResult)
+
+	retriedErrors := append(slices.Clone(reqResult.retriedErrors), attemptError(reqResult.statusCode, reqResult.error))
+	g.retriedErrors.Store(failedUrl, retriedErrors)
+	if err = request.Retry(); err != nil {
+		g.retriedErrors.Delete(failedUrl)
+		g.runningRequests.Store(reqResult.id, reqResult, failedUrl)
+		g.runningRequests.Done(reqResult)
+		g.requestCount.Add(1)
+		g.responseCount.Add(1)
+		g.updateStatusBar(reqResult)
+		g.failedRequests.Store(failedUrl, depth)
+		return err
+	}
+	return nil
+}
+
+// attemptError describes a failed attempt by its status code or its error
+func attemptError(statusCode int, err error) string {
+	if statusCode > 0 {
+		return fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "Error"
+}
@@ -0,0 +1,127 @@
+package grawl
+
+import (
+	"context"
+	"errors"
+	"github.com/gocolly/colly/v2"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyIsRetryable(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryOn    []string
+		statusCode int
+		err        error
+		expected   bool
+	}{
+		{name: "status code in range", retryOn: DefaultRetryOn, statusCode: 503, expected: true},
+		{name: "status code not in range", retryOn: DefaultRetryOn, statusCode: 500, expected: false},
+		{name: "single status code", retryOn: []string{"429"}, statusCode: 429, expected: true},
+		{name: "not found", retryOn: DefaultRetryOn, statusCode: 404, err: errors.New("Not Found"), expected: false},
+		{name: "timeout", retryOn: DefaultRetryOn, err: &url.Error{Op: "Get", URL: "https://example.com", Err: context.DeadlineExceeded}, expected: true},
+		{name: "404 with keywords only", retryOn: []string{RetryOnTimeout}, statusCode: 404, err: errors.New("Not Found"), expected: false},
+		{name: "503 with keywords only", retryOn: []string{RetryOnTimeout, RetryOnConnection}, statusCode: 503, err: errors.New("Service Unavailable"), expected: false},
+		{name: "status codes with whitespace only", retryOn: []string{" "}, statusCode: 503, expected: false},
+		{name: "timeout not retried", retryOn: []string{RetryOnConnection}, err: context.DeadlineExceeded, expected: false},
+		{name: "refused connection", retryOn: DefaultRetryOn, err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, expected: true},
+		{name: "reset connection", retryOn: DefaultRetryOn, err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}, expected: true},
+		{name: "closed connection", retryOn: DefaultRetryOn, err: &url.Error{Op: "Get", URL: "https://example.com", Err: io.EOF}, expected: true},
+		{name: "connection not retried", retryOn: []string{RetryOnTimeout}, err: syscall.ECONNREFUSED, expected: false},
+		{name: "other error", retryOn: DefaultRetryOn, err: errors.New("unsupported protocol scheme"), expected: false},
+		{name: "no error", retryOn: DefaultRetryOn, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := newRetryPolicy(1, 1000, test.retryOn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if retryable := policy.isRetryable(&colly.Response{StatusCode: test.statusCode}, test.err); retryable != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, retryable)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseDelayMs int64
+		attempt     int
+		retryAfter  string
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{name: "first retry", baseDelayMs: 1000, attempt: 2, expectedMin: 500 * time.Millisecond, expectedMax: time.Second},
+		{name: "second retry", baseDelayMs: 1000, attempt: 3, expectedMin: time.Second, expectedMax: 2 * time.Second},
+		{name: "third retry", baseDelayMs: 1000, attempt: 4, expectedMin: 2 * time.Second, expectedMax: 4 * time.Second},
+		{name: "max delay", baseDelayMs: 1000, attempt: 20, expectedMin: retryMaxDelay / 2, expectedMax: retryMaxDelay},
+		{name: "no delay", baseDelayMs: 0, attempt: 2, expectedMin: 0, expectedMax: 0},
+		{name: "longer retry after", baseDelayMs: 100, attempt: 2, retryAfter: "5", expectedMin: 5 * time.Second, expectedMax: 5 * time.Second},
+		{name: "shorter retry after", baseDelayMs: 4000, attempt: 2, retryAfter: "1", expectedMin: 2 * time.Second, expectedMax: 4 * time.Second},
+		{name: "retry after above the max delay", baseDelayMs: 100, attempt: 2, retryAfter: "3600", expectedMin: retryMaxDelay, expectedMax: retryMaxDelay},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := newRetryPolicy(3, test.baseDelayMs, DefaultRetryOn)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			headers := http.Header{}
+			if test.retryAfter != "" {
+				headers.Set("Retry-After", test.retryAfter)
+			}
+
+			// The delay has a random part, so it is checked several times
+			for i := 0; i < 20; i++ {
+				delay := policy.backoff(test.attempt, &colly.Response{StatusCode: 503, Headers: &headers})
+				if delay < test.expectedMin || delay > test.expectedMax {
+					t.Fatalf("expected a delay between %s and %s, got %s", test.expectedMin, test.expectedMax, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  time.Duration
+		stop     bool
+		expected bool
+	}{
+		{name: "no backoff", expected: true},
+		{name: "backoff", backoff: 50 * time.Millisecond, expected: true},
+		{name: "stopped during the backoff", backoff: time.Minute, stop: true, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := &Grawler{stopped: make(chan struct{})}
+			if test.backoff > 0 {
+				g.retryBackoffs.Store("https://example.com/", time.Now().Add(test.backoff))
+			}
+			if test.stop {
+				close(g.stopped)
+			}
+
+			start := time.Now()
+			if ok := g.waitRetryBackoff("https://example.com/"); ok != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, ok)
+			}
+			if test.expected && time.Since(start) < test.backoff {
+				t.Errorf("expected to wait %s, waited %s", test.backoff, time.Since(start))
+			}
+		})
+	}
+}
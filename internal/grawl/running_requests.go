@@ -13,6 +13,18 @@ type RunningRequests struct {
 	//requests      map[uint32]*RunningRequest
 	idByUrl       map[string]uint32
 	foundUrlOnUrl map[string]string
+	queuedUrls    map[string]QueuedUrl
+	checkOnlyUrls map[string]bool
+	// version is increased by every change, so views only need to be rebuilt when it has changed
+	version uint64
+}
+
+// QueuedUrl is an url that has been handed over to colly but has no finished result yet
+type QueuedUrl struct {
+	Url        string `json:"url"`
+	Depth      int    `json:"depth"`
+	FoundOnUrl string `json:"found_on_url"`
+	CheckOnly  bool   `json:"check_only,omitempty"`
 }
 
 //type RunningRequest struct {
@@ -25,6 +37,8 @@ func NewRunningRequests() *RunningRequests {
 		results:       make(map[uint32]*Result),
 		idByUrl:       make(map[string]uint32),
 		foundUrlOnUrl: make(map[string]string),
+		queuedUrls:    make(map[string]QueuedUrl),
+		checkOnlyUrls: make(map[string]bool),
 	}
 }
 
@@ -32,7 +46,9 @@ func (rr *RunningRequests) GetValues() *[]*Result {
 	rr.RLock()
 	values := make([]*Result, 0, len(rr.results))
 	for _, value := range rr.results {
-		values = append(values, value)
+		if value.done {
+			values = append(values, value)
+		}
 	}
 
 	// Sorting
@@ -69,6 +85,7 @@ func (rr *RunningRequests) Delete(key uint32) {
 		delete(rr.idByUrl, value.url)
 	}
 	delete(rr.results, key)
+	rr.version++
 	rr.Unlock()
 }
 
@@ -76,6 +93,7 @@ func (rr *RunningRequests) Store(requestId uint32, value *Result, url string) *R
 	rr.Lock()
 	rr.results[requestId] = value
 	rr.idByUrl[url] = requestId
+	rr.version++
 	rr.Unlock()
 	return value
 }
@@ -105,3 +123,142 @@ func (rr *RunningRequests) HasFoundUrl(url string) bool {
 	rr.Unlock()
 	return ok
 }
+
+func (rr *RunningRequests) AddQueuedUrl(url string, depth int, foundOnUrl string, checkOnly bool) {
+	rr.Lock()
+	rr.queuedUrls[url] = QueuedUrl{
+		Url:        url,
+		Depth:      depth,
+		FoundOnUrl: foundOnUrl,
+		CheckOnly:  checkOnly,
+	}
+	if checkOnly {
+		rr.checkOnlyUrls[url] = true
+	}
+	rr.version++
+	rr.Unlock()
+}
+
+// IsCheckOnlyUrl checks if the url is only requested and its links are not followed
+func (rr *RunningRequests) IsCheckOnlyUrl(url string) bool {
+	rr.RLock()
+	defer rr.RUnlock()
+	return rr.checkOnlyUrls[url]
+}
+
+func (rr *RunningRequests) RemoveCheckOnlyUrl(url string) {
+	rr.Lock()
+	delete(rr.checkOnlyUrls, url)
+	if queuedUrl, ok := rr.queuedUrls[url]; ok {
+		queuedUrl.CheckOnly = false
+		rr.queuedUrls[url] = queuedUrl
+	}
+	rr.version++
+	rr.Unlock()
+}
+
+func (rr *RunningRequests) QueuedCount() int {
+	rr.RLock()
+	defer rr.RUnlock()
+	return len(rr.queuedUrls)
+}
+
+// Version returns the number of changes, it changes whenever a result or a queued url has changed
+func (rr *RunningRequests) Version() uint64 {
+	rr.RLock()
+	defer rr.RUnlock()
+	return rr.version
+}
+
+// QueuedUrls returns the queued urls sorted by depth and url
+func (rr *RunningRequests) QueuedUrls() []QueuedUrl {
+	rr.RLock()
+	queued := make([]QueuedUrl, 0, len(rr.queuedUrls))
+	for _, queuedUrl := range rr.queuedUrls {
+		queued = append(queued, queuedUrl)
+	}
+	rr.RUnlock()
+
+	sort.Slice(queued, func(i, j int) bool {
+		if queued[i].Depth != queued[j].Depth {
+			return queued[i].Depth < queued[j].Depth
+		}
+		return queued[i].Url < queued[j].Url
+	})
+	return queued
+}
+
+// IsRunning checks if the url has been requested and its result is not finished yet
+func (rr *RunningRequests) IsRunning(url string) bool {
+	rr.RLock()
+	defer rr.RUnlock()
+	id, ok := rr.idByUrl[url]
+	if !ok {
+		return false
+	}
+	result, ok := rr.results[id]
+	return ok && !result.done
+}
+
+func (rr *RunningRequests) RemoveQueuedUrl(url string) {
+	rr.Lock()
+	delete(rr.queuedUrls, url)
+	rr.version++
+	rr.Unlock()
+}
+
+// Done marks the result as finished. Only finished results are part of a snapshot.
+func (rr *RunningRequests) Done(result *Result) {
+	rr.Lock()
+	result.done = true
+	delete(rr.queuedUrls, result.initialRequestUrl)
+	rr.version++
+	rr.Unlock()
+}
+
+// Update changes a result under the lock, e.g. a finished result which is part of a snapshot
+func (rr *RunningRequests) Update(requestId uint32, update func(result *Result)) {
+	rr.Lock()
+	defer rr.Unlock()
+
+	if result, ok := rr.results[requestId]; ok {
+		update(result)
+		rr.version++
+	}
+}
+
+// Snapshot returns a consistent copy of the queued urls, the found urls and the finished results
+func (rr *RunningRequests) Snapshot() (queued []QueuedUrl, foundUrls map[string]string, results []resultJson) {
+	rr.RLock()
+	defer rr.RUnlock()
+
+	queued = make([]QueuedUrl, 0, len(rr.queuedUrls))
+	for _, queuedUrl := range rr.queuedUrls {
+		queued = append(queued, queuedUrl)
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].Url < queued[j].Url
+	})
+
+	foundUrls = make(map[string]string, len(rr.foundUrlOnUrl))
+	for url, foundOnUrl := range rr.foundUrlOnUrl {
+		foundUrls[url] = foundOnUrl
+	}
+
+	done := make([]*Result, 0, len(rr.results))
+	for _, result := range rr.results {
+		if result.done {
+			done = append(done, result)
+		}
+	}
+	sort.Slice(done, func(i, j int) bool {
+		return done[i].Index < done[j].Index
+	})
+
+	results = make([]resultJson, 0, len(done))
+	for _, result := range done {
+		results = append(results, newResultJson(result))
+	}
+
+	return queued, foundUrls, results
+}
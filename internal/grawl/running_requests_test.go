@@ -0,0 +1,68 @@
+package grawl
+
+import (
+	"testing"
+)
+
+func TestRunningRequestsVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(rr *RunningRequests)
+		expected bool
+	}{
+		{
+			name:     "store a result",
+			change:   func(rr *RunningRequests) { rr.Store(2, &Result{initialRequestUrl: "/b"}, "/b") },
+			expected: true,
+		},
+		{
+			name:     "finish a result",
+			change:   func(rr *RunningRequests) { r, _ := rr.Load(1); rr.Done(r) },
+			expected: true,
+		},
+		{
+			name:     "update a result",
+			change:   func(rr *RunningRequests) { rr.Update(1, func(result *Result) { result.statusCode = 500 }) },
+			expected: true,
+		},
+		{
+			name:     "update a missing result",
+			change:   func(rr *RunningRequests) { rr.Update(3, func(result *Result) {}) },
+			expected: false,
+		},
+		{
+			name:     "delete a result",
+			change:   func(rr *RunningRequests) { rr.Delete(1) },
+			expected: true,
+		},
+		{
+			name:     "queue an url",
+			change:   func(rr *RunningRequests) { rr.AddQueuedUrl("/c", 1, "/a", false) },
+			expected: true,
+		},
+		{
+			name:     "remove a queued url",
+			change:   func(rr *RunningRequests) { rr.RemoveQueuedUrl("/a") },
+			expected: true,
+		},
+		{
+			name:     "read the results",
+			change:   func(rr *RunningRequests) { rr.GetValues(); rr.QueuedUrls(); rr.IsRunning("/a") },
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rr := NewRunningRequests()
+			rr.AddQueuedUrl("/a", 0, "", false)
+			rr.Store(1, &Result{initialRequestUrl: "/a"}, "/a")
+
+			version := rr.Version()
+			test.change(rr)
+			if changed := rr.Version() != version; changed != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package grawl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// errGrawlingStopped is returned for requests which have not been sent before the grawling stopped
+var errGrawlingStopped = errors.New("grawling stopped")
+
+// stop prevents new requests. Running requests are allowed to finish.
+func (g *Grawler) stop(exitCode ExitCode, message string) {
+	g.stopOnce.Do(func() {
+		g.stopGrawling(exitCode, message)
+	})
+}
+
+// stopGrawling is called once by stop or stopByBudget
+func (g *Grawler) stopGrawling(exitCode ExitCode, message string) {
+	g.stopExitCode = exitCode
+	g.stopping.Store(true)
+	fmt.Fprintln(g.out(), message)
+	close(g.stopped)
+	if g.throttle != nil {
+		g.throttle.stop()
+	}
+}
+
+func (g *Grawler) isStopping() bool {
+	return g.stopping.Load()
+}
+
+// handleSignals stops the grawling on the first interrupt and exits immediately on the second
+func (g *Grawler) handleSignals() func() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(g.out(), "")
+			g.stop(ExitCodeInterrupted, "Grawling interrupted. Waiting for running requests to finish, interrupt again to exit immediately.")
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			fmt.Fprintln(g.out(), "Grawling aborted.")
+			g.closeFileWriter()
+			os.Exit(int(ExitCodeInterrupted))
+		case <-done:
+			return
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// wait blocks until all requests are finished. After the grawling has been stopped
+// running requests get the shutdown timeout to finish.
+func (g *Grawler) wait() {
+	finished := make(chan struct{})
+	go func() {
+		g.waitCollectors()
+		// A decision about an error of the last requests may retry the url
+		for g.errorController != nil && g.errorController.waitIdle(g.stopped) {
+			g.waitCollectors()
+		}
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return
+	case <-g.stopped:
+	}
+
+	timeout := time.Duration(g.flags.FlagShutdownTimeout * float32(time.Second))
+	select {
+	case <-finished:
+	case <-time.After(timeout):
+		fmt.Fprintf(g.out(), "Running requests did not finish within %s.\n", timeout)
+	}
+}
+
+// waitCollectors waits for the requests of the grawling and then for the second requests of --warm-twice, which
+// are queued by the last responses of the grawling
+func (g *Grawler) waitCollectors() {
+	g.collector.Wait()
+	if g.warmCollector != nil {
+		g.warmCollector.Wait()
+	}
+}
+
+// failWriting stops the grawling at the first error writing the output file, crawl returns the error
+func (g *Grawler) failWriting(err error) {
+	err = fmt.Errorf("Error writing output file: %w", err)
+	if g.writeErr.CompareAndSwap(nil, &err) {
+		g.stop(ExitCodeConfigError, err.Error()+". Waiting for running requests to finish.")
+	}
+}
+
+// closeOutputFile closes the output file after the grawling and returns the first error writing it
+func (g *Grawler) closeOutputFile() error {
+	if g.fileWriter != nil {
+		if err := g.fileWriter.Close(); err != nil {
+			err = fmt.Errorf("Error closing output file: %w", err)
+			g.writeErr.CompareAndSwap(nil, &err)
+		}
+	}
+
+	if err := g.writeErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (g *Grawler) closeFileWriter() {
+	if g.fileWriter == nil {
+		return
+	}
+
+	if err := g.fileWriter.Close(); err != nil {
+		fmt.Fprintln(g.out(), "Error closing output file:", err)
+	}
+}
@@ -0,0 +1,111 @@
+package grawl
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/gocolly/colly/v2"
+	"net/url"
+	"strings"
+)
+
+const (
+	robotsTxtPath      = "/robots.txt"
+	defaultSitemapPath = "/sitemap.xml"
+)
+
+// sitemapStartUrl returns the robots.txt of a site root to discover its sitemaps, other urls are used as they are
+func sitemapStartUrl(grawlUrl string) string {
+	parsedUrl, err := url.Parse(grawlUrl)
+	if err != nil || (parsedUrl.Path != "" && parsedUrl.Path != "/") || parsedUrl.RawQuery != "" {
+		return grawlUrl
+	}
+
+	parsedUrl.Path = robotsTxtPath
+	return parsedUrl.String()
+}
+
+func isRobotsTxtResponse(r *colly.Response) bool {
+	return strings.EqualFold(r.Request.URL.Path, robotsTxtPath)
+}
+
+// sitemapFallbackUrl returns the default sitemap which is grawled if the robots.txt a sitemap grawling has been
+// started with could not be grawled. It is empty for other grawlings.
+func (g *Grawler) sitemapFallbackUrl(grawlUrl string) string {
+	parsedUrl, err := url.Parse(grawlUrl)
+	if !g.flags.FlagSitemap || err != nil || !strings.EqualFold(parsedUrl.Path, robotsTxtPath) {
+		return ""
+	}
+	return parsedUrl.ResolveReference(&url.URL{Path: defaultSitemapPath}).String()
+}
+
+// registerSitemapHandlers visits the urls of sitemaps, sitemap indexes and the sitemaps of a robots.txt
+func (g *Grawler) registerSitemapHandlers(c *colly.Collector) {
+	visitLoc := func(e *colly.XMLElement) string {
+		loc := strings.TrimSpace(e.Text)
+		if loc == "" {
+			return ""
+		}
+		locUrl := e.Request.AbsoluteURL(loc)
+		g.visit(c, e.Request, locUrl, e.Request.URL.String(), true)
+		return locUrl
+	}
+
+	c.OnXML("//urlset/url/loc", func(e *colly.XMLElement) {
+		if pageUrl := visitLoc(e); pageUrl != "" {
+			g.sitemapPages.Store(pageUrl, true)
+		}
+	})
+	c.OnXML("//sitemapindex/sitemap/loc", func(e *colly.XMLElement) {
+		visitLoc(e)
+	})
+
+	// Colly does not call OnResponse for a missing robots.txt, the default sitemap is grawled instead
+	c.OnError(func(r *colly.Response, err error) {
+		if !isRobotsTxtResponse(r) {
+			return
+		}
+		// Retried requests and requests which have not been sent have no result anymore
+		if _, ok := g.runningRequests.Load(r.Request.ID); !ok {
+			return
+		}
+		g.visit(c, r.Request, r.Request.AbsoluteURL(defaultSitemapPath), r.Request.URL.String(), true)
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		if !isRobotsTxtResponse(r) {
+			return
+		}
+
+		sitemapUrls := parseRobotsTxtSitemaps(r.Body)
+
+		// Most sites without a sitemap line still have a sitemap at the default location
+		if len(sitemapUrls) == 0 {
+			sitemapUrls = append(sitemapUrls, defaultSitemapPath)
+		}
+
+		for _, sitemapUrl := range sitemapUrls {
+			g.visit(c, r.Request, r.Request.AbsoluteURL(sitemapUrl), r.Request.URL.String(), true)
+		}
+	})
+}
+
+// parseRobotsTxtSitemaps returns the urls of all "Sitemap:" lines of a robots.txt
+func parseRobotsTxtSitemaps(body []byte) []string {
+	sitemapUrls := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, found := strings.Cut(line, ":")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+			continue
+		}
+
+		// Remove comments at the end of the line
+		value, _, _ = strings.Cut(value, "#")
+		value = strings.TrimSpace(value)
+		if value != "" {
+			sitemapUrls = append(sitemapUrls, value)
+		}
+	}
+	return sitemapUrls
+}
@@ -0,0 +1,193 @@
+package grawl
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const sitemapXmlns = `xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"`
+
+func newSitemapTestServer(t *testing.T, robotsTxt string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	writeXml := func(w http.ResponseWriter, body string) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>`+body)
+	}
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if robotsTxt == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.ReplaceAll(robotsTxt, "{server}", server.URL))
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, fmt.Sprintf(`<urlset %s>
+  <url>
+    <loc>
+      %s/page-1
+    </loc>
+  </url>
+  <url><loc>%s/page-2</loc></url>
+</urlset>`, sitemapXmlns, server.URL, server.URL))
+	})
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, fmt.Sprintf(`<sitemapindex %s><sitemap><loc>%s/sitemap-nested-index.xml</loc></sitemap></sitemapindex>`, sitemapXmlns, server.URL))
+	})
+	mux.HandleFunc("/sitemap-nested-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		writeXml(w, fmt.Sprintf(`<sitemapindex %s><sitemap><loc>%s/sitemap.xml</loc></sitemap><sitemap><loc>%s/sitemap.xml.gz</loc></sitemap></sitemapindex>`, sitemapXmlns, server.URL, server.URL))
+	})
+	mux.HandleFunc("/sitemap.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-gzip")
+		gz := gzip.NewWriter(w)
+		fmt.Fprintf(gz, `<?xml version="1.0" encoding="UTF-8"?><urlset %s><url><loc>%s/page-3</loc></url></urlset>`, sitemapXmlns, server.URL)
+		gz.Close()
+	})
+	for _, page := range []string{"/page-1", "/page-2", "/page-3"} {
+		mux.HandleFunc(page, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body><a href="/not-in-sitemap">link</a></body></html>`)
+		})
+	}
+
+	return server
+}
+
+// grawlSitemap grawls the url in sitemap mode and returns the paths of all results
+func grawlSitemap(t *testing.T, grawlUrl string) []string {
+	t.Helper()
+
+	grawler, err := NewGrawler(Flags{
+		FlagSitemap:        true,
+		FlagParallel:       2,
+		FlagRequestTimeout: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exitCode := grawler.Grawl(grawlUrl); exitCode != ExitCodeOk {
+		t.Fatalf("expected exit code %d, got %d", ExitCodeOk, exitCode)
+	}
+
+	paths := make([]string, 0)
+	for _, result := range *grawler.runningRequests.GetValues() {
+		paths = append(paths, result.urlPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestGrawlSitemap(t *testing.T) {
+	server := newSitemapTestServer(t, "")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "urlset",
+			path:     "/sitemap.xml",
+			expected: []string{"/page-1", "/page-2", "/sitemap.xml"},
+		},
+		{
+			name:     "gzip",
+			path:     "/sitemap.xml.gz",
+			expected: []string{"/page-3", "/sitemap.xml.gz"},
+		},
+		{
+			name: "nested sitemap indexes",
+			path: "/sitemap-index.xml",
+			expected: []string{
+				"/page-1", "/page-2", "/page-3",
+				"/sitemap-index.xml", "/sitemap-nested-index.xml", "/sitemap.xml", "/sitemap.xml.gz",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paths := grawlSitemap(t, server.URL+test.path)
+			if !slices.Equal(paths, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, paths)
+			}
+		})
+	}
+}
+
+func TestGrawlSitemapFromRobotsTxt(t *testing.T) {
+	tests := []struct {
+		name      string
+		robotsTxt string
+		expected  []string
+	}{
+		{
+			name:      "sitemap lines",
+			robotsTxt: "User-agent: *\nDisallow: /private\n\nSitemap: {server}/sitemap-index.xml # all sitemaps\n",
+			expected: []string{
+				"/page-1", "/page-2", "/page-3",
+				"/robots.txt", "/sitemap-index.xml", "/sitemap-nested-index.xml", "/sitemap.xml", "/sitemap.xml.gz",
+			},
+		},
+		{
+			name:      "default sitemap",
+			robotsTxt: "User-agent: *\nDisallow:\n",
+			expected:  []string{"/page-1", "/page-2", "/robots.txt", "/sitemap.xml"},
+		},
+		{
+			name:      "missing robots.txt",
+			robotsTxt: "",
+			expected:  []string{"/page-1", "/page-2", "/robots.txt", "/sitemap.xml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newSitemapTestServer(t, test.robotsTxt)
+			paths := grawlSitemap(t, server.URL)
+			if !slices.Equal(paths, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, paths)
+			}
+		})
+	}
+}
+
+func TestGrawlSitemapWithoutRobotsTxtAndSitemap(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	grawler, err := NewGrawler(Flags{
+		FlagSitemap:        true,
+		FlagParallel:       2,
+		FlagRequestTimeout: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exitCode := grawler.Grawl(server.URL); exitCode != ExitCodeStartUrlFailed {
+		t.Errorf("expected exit code %d, got %d", ExitCodeStartUrlFailed, exitCode)
+	}
+}
+
+func TestParseRobotsTxtSitemaps(t *testing.T) {
+	robotsTxt := "User-agent: *\nsitemap: https://example.com/a.xml\nSITEMAP:https://example.com/b.xml.gz # gzip\nSitemap:\n"
+	expected := []string{"https://example.com/a.xml", "https://example.com/b.xml.gz"}
+
+	if sitemapUrls := parseRobotsTxtSitemaps([]byte(robotsTxt)); !slices.Equal(sitemapUrls, expected) {
+		t.Errorf("expected %v, got %v", expected, sitemapUrls)
+	}
+}
@@ -0,0 +1,171 @@
+package grawl
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	sitemapMaxUrls  = 50000
+	sitemapMaxBytes = 50 * 1024 * 1024
+
+	sitemapHeader      = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n"
+	sitemapFooter      = "</urlset>\n"
+	sitemapIndexHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n"
+	sitemapIndexFooter = "</sitemapindex>\n"
+	sitemapDateFormat  = "2006-01-02T15:04:05Z07:00"
+)
+
+// registerSitemapPageInfo collects the robots meta tag and the canonical url of the pages for --write-sitemap
+func (g *Grawler) registerSitemapPageInfo(c *colly.Collector) {
+	c.OnHTML("meta[name][content]", func(e *colly.HTMLElement) {
+		if !strings.EqualFold(e.Attr("name"), "robots") || !isNoindex(e.Attr("content")) {
+			return
+		}
+		g.runningRequests.Update(e.Request.ID, func(result *Result) {
+			result.noindex = true
+		})
+	})
+
+	c.OnHTML("link[rel][href]", func(e *colly.HTMLElement) {
+		if !slices.Contains(strings.Fields(strings.ToLower(e.Attr("rel"))), "canonical") {
+			return
+		}
+		canonicalUrl := e.Request.AbsoluteURL(e.Attr("href"))
+		g.runningRequests.Update(e.Request.ID, func(result *Result) {
+			result.canonicalUrl = canonicalUrl
+		})
+	})
+}
+
+// isNoindex checks the content of a robots meta tag or a X-Robots-Tag header
+func isNoindex(robots string) bool {
+	for _, directive := range strings.Split(strings.ToLower(robots), ",") {
+		// Directives of a X-Robots-Tag header can be limited to a user agent, e.g. "googlebot: noindex"
+		if _, agentDirective, found := strings.Cut(directive, ":"); found {
+			directive = agentDirective
+		}
+		directive = strings.TrimSpace(directive)
+		if directive == "noindex" || directive == "none" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSitemapPage checks if the result is a successful html page which should be indexed
+func isSitemapPage(result *Result) bool {
+	if result.HasError() || result.HasRedirects() || result.IsRedirected() || result.noindex {
+		return false
+	}
+
+	if result.statusCode < 200 || result.statusCode >= 300 || !isHtmlContentType(result.contentType) {
+		return false
+	}
+
+	// Pages which are canonicalised to another url
+	return result.canonicalUrl == "" || urlWithoutFragment(result.canonicalUrl) == urlWithoutFragment(result.url)
+}
+
+// checkSitemapBaseUrl validates --sitemap-base-url, it has to be an absolute http or https url
+func checkSitemapBaseUrl(baseUrl string) error {
+	parsedUrl, err := url.Parse(baseUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return fmt.Errorf("invalid sitemap base url \"%s\": use an absolute url like \"https://example.com/sitemaps/\"", baseUrl)
+	}
+	return nil
+}
+
+// sitemapBaseUrl returns the url under which the sitemaps of an index are published, by default the root of the grawled website
+func sitemapBaseUrl(baseUrlFlag string, grawlUrl string) string {
+	if baseUrlFlag != "" {
+		return baseUrlFlag
+	}
+
+	parsedUrl, err := url.Parse(grawlUrl)
+	if err != nil {
+		return grawlUrl
+	}
+	return (&url.URL{Scheme: parsedUrl.Scheme, Host: parsedUrl.Host, Path: "/"}).String()
+}
+
+// writeSitemap writes the pages to a sitemap. Above 50,000 urls or 50 MB the pages are split into multiple
+// sitemaps and the file becomes a sitemap index, which links the sitemaps under the base url.
+func writeSitemap(filePath string, baseUrl string, results []*Result, withLastmod bool) (int, error) {
+	sitemaps := make([][]byte, 0)
+	current := bytes.NewBufferString(sitemapHeader)
+	currentUrls := 0
+	pages := 0
+
+	for _, result := range results {
+		if !isSitemapPage(result) {
+			continue
+		}
+
+		entry := newSitemapEntry(result.url, result.lastModified, withLastmod)
+		if currentUrls >= sitemapMaxUrls || current.Len()+len(entry)+len(sitemapFooter) > sitemapMaxBytes {
+			current.WriteString(sitemapFooter)
+			sitemaps = append(sitemaps, current.Bytes())
+			current = bytes.NewBufferString(sitemapHeader)
+			currentUrls = 0
+		}
+
+		current.WriteString(entry)
+		currentUrls++
+		pages++
+	}
+	current.WriteString(sitemapFooter)
+	sitemaps = append(sitemaps, current.Bytes())
+
+	if len(sitemaps) == 1 {
+		return pages, os.WriteFile(filePath, sitemaps[0], 0644)
+	}
+
+	// The sitemaps are linked relative to the directory of the base url
+	if !strings.HasSuffix(baseUrl, "/") {
+		baseUrl += "/"
+	}
+	parsedBaseUrl, err := url.Parse(baseUrl)
+	if err != nil {
+		return pages, err
+	}
+
+	extension := filepath.Ext(filePath)
+	baseName := strings.TrimSuffix(filepath.Base(filePath), extension)
+	index := bytes.NewBufferString(sitemapIndexHeader)
+
+	for i, sitemap := range sitemaps {
+		fileName := fmt.Sprintf("%s-%d%s", baseName, i+1, extension)
+		if err = os.WriteFile(filepath.Join(filepath.Dir(filePath), fileName), sitemap, 0644); err != nil {
+			return pages, err
+		}
+
+		sitemapUrl := parsedBaseUrl.ResolveReference(&url.URL{Path: fileName})
+		index.WriteString("  <sitemap>\n    <loc>" + escapeXml(sitemapUrl.String()) + "</loc>\n  </sitemap>\n")
+	}
+	index.WriteString(sitemapIndexFooter)
+
+	return pages, os.WriteFile(filePath, index.Bytes(), 0644)
+}
+
+func newSitemapEntry(pageUrl string, lastModified time.Time, withLastmod bool) string {
+	entry := "  <url>\n    <loc>" + escapeXml(pageUrl) + "</loc>\n"
+	if withLastmod && !lastModified.IsZero() {
+		entry += "    <lastmod>" + lastModified.UTC().Format(sitemapDateFormat) + "</lastmod>\n"
+	}
+	return entry + "  </url>\n"
+}
+
+func escapeXml(text string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
@@ -0,0 +1,165 @@
+package grawl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSitemapPages returns html pages whose urls are padded to the given length
+func newSitemapPages(t *testing.T, count int, urlLength int) []*Result {
+	t.Helper()
+
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := make([]*Result, 0, count)
+	for i := 0; i < count; i++ {
+		pageUrl := fmt.Sprintf("https://example.com/page-%d", i)
+		pageUrl += strings.Repeat("x", max(0, urlLength-len(pageUrl)))
+		results = append(results, &Result{
+			url:                 pageUrl,
+			statusCode:          200,
+			contentType:         "text/html; charset=utf-8",
+			httpErrorCodeRanges: errorCodeRanges,
+		})
+	}
+	return results
+}
+
+func TestWriteSitemap(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         int
+		urlLength     int
+		baseUrl       string
+		expectedFiles []string
+		expectedLoc   string
+	}{
+		{
+			name:          "single sitemap",
+			pages:         sitemapMaxUrls,
+			expectedFiles: []string{"sitemap.xml"},
+		},
+		{
+			name:          "split at 50,000 urls",
+			pages:         sitemapMaxUrls + 1,
+			baseUrl:       "https://example.com/sitemaps",
+			expectedFiles: []string{"sitemap-1.xml", "sitemap-2.xml", "sitemap.xml"},
+			expectedLoc:   "<loc>https://example.com/sitemaps/sitemap-2.xml</loc>",
+		},
+		{
+			name:          "split at 50 MB",
+			pages:         30000,
+			urlLength:     1800,
+			baseUrl:       "https://example.com/",
+			expectedFiles: []string{"sitemap-1.xml", "sitemap-2.xml", "sitemap.xml"},
+			expectedLoc:   "<loc>https://example.com/sitemap-1.xml</loc>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			filePath := filepath.Join(dir, "sitemap.xml")
+
+			pages, err := writeSitemap(filePath, test.baseUrl, newSitemapPages(t, test.pages, test.urlLength), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pages != test.pages {
+				t.Errorf("expected %d pages, got %d", test.pages, pages)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := make([]string, 0)
+			urls := 0
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+
+				content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(content) > sitemapMaxBytes {
+					t.Errorf("expected at most %d bytes in %s, got %d", sitemapMaxBytes, entry.Name(), len(content))
+				}
+				if strings.Count(string(content), "<url>") > sitemapMaxUrls {
+					t.Errorf("expected at most %d urls in %s", sitemapMaxUrls, entry.Name())
+				}
+				urls += strings.Count(string(content), "<url>")
+
+				if test.expectedLoc != "" && entry.Name() == "sitemap.xml" && !strings.Contains(string(content), test.expectedLoc) {
+					t.Errorf("expected %s in the sitemap index", test.expectedLoc)
+				}
+			}
+
+			if strings.Join(files, ",") != strings.Join(test.expectedFiles, ",") {
+				t.Errorf("expected %v, got %v", test.expectedFiles, files)
+			}
+			if urls != test.pages {
+				t.Errorf("expected %d urls in the sitemaps, got %d", test.pages, urls)
+			}
+		})
+	}
+}
+
+func TestIsSitemapPage(t *testing.T) {
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		result   Result
+		expected bool
+	}{
+		{
+			name:     "html page",
+			result:   Result{url: "https://example.com/a", statusCode: 200, contentType: "text/html"},
+			expected: true,
+		},
+		{
+			name:     "canonical to itself",
+			result:   Result{url: "https://example.com/a", statusCode: 200, contentType: "text/html", canonicalUrl: "https://example.com/a#top"},
+			expected: true,
+		},
+		{
+			name:   "canonical to another url",
+			result: Result{url: "https://example.com/a?page=2", statusCode: 200, contentType: "text/html", canonicalUrl: "https://example.com/a"},
+		},
+		{
+			name:   "noindex",
+			result: Result{url: "https://example.com/a", statusCode: 200, contentType: "text/html", noindex: true},
+		},
+		{
+			name:   "not html",
+			result: Result{url: "https://example.com/a.png", statusCode: 200, contentType: "image/png"},
+		},
+		{
+			name:   "error",
+			result: Result{url: "https://example.com/a", statusCode: 404, contentType: "text/html"},
+		},
+		{
+			name:   "redirected",
+			result: Result{url: "https://example.com/b", statusCode: 200, contentType: "text/html", urlRedirectedFrom: "https://example.com/a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.result.httpErrorCodeRanges = errorCodeRanges
+			if isPage := isSitemapPage(&test.result); isPage != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, isPage)
+			}
+		})
+	}
+}
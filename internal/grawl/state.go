@@ -0,0 +1,192 @@
+package grawl
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	stateFileName = "state.json"
+	stateVersion  = 2
+)
+
+// State is the saved state of a grawling. It is used to resume an interrupted grawling.
+type State struct {
+	Version  int          `json:"version"`
+	GrawlUrl string       `json:"grawl_url"`
+	SavedAt  string       `json:"saved_at"`
+	Flags    Flags        `json:"flags"`
+	Frontier []QueuedUrl  `json:"frontier"`
+	Visited  []string     `json:"visited"`
+	Results  []resultJson `json:"results"`
+}
+
+// LoadState reads the state of an interrupted grawling from the state directory
+func LoadState(stateDir string) (*State, error) {
+	content, err := os.ReadFile(filepath.Join(stateDir, stateFileName))
+	if err != nil {
+		return nil, fmt.Errorf("could not read state: %v", err)
+	}
+
+	var state State
+	if err = json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("could not parse state: %v", err)
+	}
+
+	if state.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported state version %d", state.Version)
+	}
+
+	// Keep saving the state to the directory it has been loaded from
+	state.Flags.FlagStateDir = stateDir
+
+	return &state, nil
+}
+
+// saveState writes the current state atomically to the state directory
+func (g *Grawler) saveState(grawlUrl string) error {
+	g.stateMutex.Lock()
+	defer g.stateMutex.Unlock()
+
+	// The results are copied under the lock, the callbacks of colly may still change them
+	queued, foundUrls, results := g.runningRequests.Snapshot()
+
+	state := State{
+		Version:  stateVersion,
+		GrawlUrl: grawlUrl,
+		SavedAt:  time.Now().Format(time.RFC3339),
+		Flags:    g.flags,
+		Frontier: queued,
+		Visited:  make([]string, 0, len(foundUrls)),
+		Results:  results,
+	}
+
+	queuedUrls := make(map[string]bool, len(queued))
+	for _, queuedUrl := range queued {
+		queuedUrls[queuedUrl.Url] = true
+	}
+
+	for url := range foundUrls {
+		if !queuedUrls[url] {
+			state.Visited = append(state.Visited, url)
+		}
+	}
+	sort.Strings(state.Visited)
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(g.flags.FlagStateDir, 0750); err != nil {
+		return err
+	}
+
+	stateFile := filepath.Join(g.flags.FlagStateDir, stateFileName)
+	if err = os.WriteFile(stateFile+".tmp", content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(stateFile+".tmp", stateFile)
+}
+
+// startStateCheckpoints saves the state periodically until the returned function is called
+func (g *Grawler) startStateCheckpoints(grawlUrl string) func() {
+	interval := time.Duration(g.flags.FlagStateInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := g.saveState(grawlUrl); err != nil {
+					fmt.Fprintln(g.out(), "Error saving state:", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// newCollyRequest creates a request of the url with a depth, e.g. to continue a queued url
+func newCollyRequest(c *colly.Collector, url string, depth int) (*colly.Request, error) {
+	// Colly only creates requests with a certain depth from serialized requests
+	serialized, err := json.Marshal(map[string]interface{}{
+		"URL":     url,
+		"Method":  http.MethodGet,
+		"Depth":   depth,
+		"Headers": http.Header{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.UnmarshalRequest(serialized)
+}
+
+// restoreState adds the results and visited urls of the state and requests the urls of the frontier
+func (g *Grawler) restoreState(c *colly.Collector, state *State) error {
+	// Restored results get ids which are never used by colly
+	restoredId := uint32(math.MaxUint32)
+
+	for _, stateResult := range state.Results {
+		result, err := newResultFromJson(stateResult, g.responseErrorRanges)
+		if err != nil {
+			return fmt.Errorf("could not restore result of %s: %v", stateResult.Url, err)
+		}
+
+		result.id = restoredId
+		g.runningRequests.Store(restoredId, result, result.initialRequestUrl)
+		g.runningRequests.AddFoundUrl(result.initialRequestUrl, result.foundOnUrl)
+		g.runningRequests.Done(result)
+		g.restoredUrls[result.initialRequestUrl] = true
+		g.restoredUrls[result.url] = true
+		g.requestCount.Add(1)
+		g.responseCount.Add(1)
+		if result.IsRedirected() {
+			g.redirections.Add(1)
+		}
+		restoredId--
+	}
+
+	for _, url := range state.Visited {
+		g.runningRequests.AddFoundUrl(url, "")
+	}
+
+	for _, queuedUrl := range state.Frontier {
+		g.runningRequests.AddFoundUrl(queuedUrl.Url, queuedUrl.FoundOnUrl)
+	}
+
+	fmt.Fprintf(g.out(), "Restored %d results, %d urls left to grawl.\n", len(state.Results), len(state.Frontier))
+
+	for _, queuedUrl := range state.Frontier {
+		request, err := newCollyRequest(c, queuedUrl.Url, queuedUrl.Depth)
+		if err != nil {
+			return fmt.Errorf("could not restore request of %s: %v", queuedUrl.Url, err)
+		}
+
+		g.runningRequests.AddQueuedUrl(queuedUrl.Url, queuedUrl.Depth, queuedUrl.FoundOnUrl, queuedUrl.CheckOnly)
+		if err = request.Do(); err != nil {
+			g.runningRequests.RemoveQueuedUrl(queuedUrl.Url)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package grawl
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gocolly/colly/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLoadState(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		invalid bool
+	}{
+		{name: "valid state", content: fmt.Sprintf(`{"version":%d,"grawl_url":"https://a.com/"}`, stateVersion)},
+		{name: "missing state", invalid: true},
+		{name: "invalid json", content: `{"version":`, invalid: true},
+		{name: "old version", content: `{"version":1,"grawl_url":"https://a.com/"}`, invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stateDir := t.TempDir()
+			if test.content != "" {
+				if err := os.WriteFile(filepath.Join(stateDir, stateFileName), []byte(test.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			state, err := LoadState(stateDir)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", state)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.GrawlUrl != "https://a.com/" {
+				t.Errorf("expected the grawl url https://a.com/, got %s", state.GrawlUrl)
+			}
+			if state.Flags.FlagStateDir != stateDir {
+				t.Errorf("expected the state dir %s, got %s", stateDir, state.Flags.FlagStateDir)
+			}
+		})
+	}
+}
+
+func TestSaveAndRestoreState(t *testing.T) {
+	var requestedMutex sync.Mutex
+	requested := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedMutex.Lock()
+		requested = append(requested, r.URL.Path)
+		requestedMutex.Unlock()
+	}))
+	defer server.Close()
+
+	newStateGrawler := func(stateDir string) *Grawler {
+		grawler, err := NewGrawler(Flags{FlagParallel: 1, FlagRequestTimeout: 5, FlagStateDir: stateDir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		grawler.SetOutput(io.Discard)
+		return grawler
+	}
+
+	stateDir := filepath.Join(t.TempDir(), "state")
+	saved := newStateGrawler(stateDir)
+
+	// A finished result, a running result and a redirected result
+	home := newJsonlTestResult(t, server.URL+"/", 200)
+	home.Index = 1
+	saved.runningRequests.Store(1, home, home.url)
+	saved.runningRequests.AddFoundUrl(home.url, "")
+	saved.runningRequests.Done(home)
+
+	running := newJsonlTestResult(t, server.URL+"/running", 0)
+	saved.runningRequests.Store(2, running, running.url)
+	saved.runningRequests.AddFoundUrl(running.url, home.url)
+
+	redirected := newJsonlTestResult(t, server.URL+"/old", 200)
+	redirected.Index = 2
+	redirected.AddRedirectHop(server.URL+"/old", 301)
+	redirected.url = server.URL + "/new"
+	redirected.urlRedirectedFrom = server.URL + "/old"
+	saved.runningRequests.Store(3, redirected, redirected.initialRequestUrl)
+	saved.runningRequests.AddFoundUrl(redirected.initialRequestUrl, home.url)
+	saved.runningRequests.Done(redirected)
+
+	saved.runningRequests.AddFoundUrl(server.URL+"/queued", home.url)
+	saved.runningRequests.AddQueuedUrl(server.URL+"/queued", 2, home.url, false)
+
+	if err := saved.saveState(home.url); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, stateFileName+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary state file to be renamed, got %v", err)
+	}
+
+	state, err := LoadState(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedVisited := []string{server.URL + "/", server.URL + "/old", server.URL + "/running"}
+	if !slices.Equal(state.Visited, expectedVisited) {
+		t.Errorf("expected visited %v, got %v", expectedVisited, state.Visited)
+	}
+	expectedFrontier := []QueuedUrl{{Url: server.URL + "/queued", Depth: 2, FoundOnUrl: home.url}}
+	if !slices.Equal(state.Frontier, expectedFrontier) {
+		t.Errorf("expected frontier %v, got %v", expectedFrontier, state.Frontier)
+	}
+	if len(state.Results) != 2 || state.Results[0].Url != home.url || state.Results[1].Url != redirected.url {
+		content, _ := json.Marshal(state.Results)
+		t.Errorf("expected the finished results, got %s", content)
+	}
+
+	restored := newStateGrawler(stateDir)
+	if err = restored.restoreState(colly.NewCollector(), state); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		url              string
+		expectedRestored bool
+		expectedFound    bool
+	}{
+		{name: "finished result", url: server.URL + "/", expectedRestored: true, expectedFound: true},
+		{name: "initial url of a redirect", url: server.URL + "/old", expectedRestored: true, expectedFound: true},
+		{name: "redirected url", url: server.URL + "/new", expectedRestored: true},
+		{name: "running result", url: server.URL + "/running", expectedFound: true},
+		{name: "queued url", url: server.URL + "/queued", expectedFound: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if restored.restoredUrls[test.url] != test.expectedRestored {
+				t.Errorf("expected restored %v, got %v", test.expectedRestored, restored.restoredUrls[test.url])
+			}
+			if restored.runningRequests.HasFoundUrl(test.url) != test.expectedFound {
+				t.Errorf("expected found %v, got %v", test.expectedFound, restored.runningRequests.HasFoundUrl(test.url))
+			}
+		})
+	}
+
+	if results := *restored.runningRequests.GetValues(); len(results) != 2 {
+		t.Errorf("expected 2 restored results, got %d", len(results))
+	}
+	if restored.requestCount.Load() != 2 || restored.redirections.Load() != 1 {
+		t.Errorf("expected 2 requests and 1 redirection, got %d and %d", restored.requestCount.Load(), restored.redirections.Load())
+	}
+	if queued := restored.runningRequests.QueuedUrls(); !slices.Equal(queued, expectedFrontier) {
+		t.Errorf("expected queued %v, got %v", expectedFrontier, queued)
+	}
+
+	requestedMutex.Lock()
+	defer requestedMutex.Unlock()
+	if !slices.Equal(requested, []string{"/queued"}) {
+		t.Errorf("expected the frontier to be requested, got %v", requested)
+	}
+}
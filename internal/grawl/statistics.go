@@ -0,0 +1,234 @@
+package grawl
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	histogramBarWidth    = 40
+	statisticsGroupWidth = 30
+)
+
+// statisticsPercentiles are the percentiles of the response times in the summary
+var statisticsPercentiles = []float64{50, 75, 90, 95, 99}
+
+// histogramBuckets are the upper bounds of the response time histogram, the last bucket has no upper bound
+var histogramBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// durationStats are the statistics of response times. They are calculated from the finished results
+// after the grawling, so no counters are shared between the callbacks of parallel requests.
+type durationStats struct {
+	sorted []time.Duration
+	total  time.Duration
+	avg    time.Duration
+	stdDev time.Duration
+}
+
+func newDurationStats(durations []time.Duration) durationStats {
+	stats := durationStats{sorted: slices.Sorted(slices.Values(durations))}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	for _, duration := range durations {
+		stats.total += duration
+	}
+	stats.avg = stats.total / time.Duration(len(durations))
+
+	variance := 0.0
+	for _, duration := range durations {
+		diff := float64(duration - stats.avg)
+		variance += diff * diff
+	}
+	stats.stdDev = time.Duration(math.Sqrt(variance / float64(len(durations))))
+
+	return stats
+}
+
+func (s durationStats) count() int {
+	return len(s.sorted)
+}
+
+func (s durationStats) min() time.Duration {
+	if len(s.sorted) == 0 {
+		return 0
+	}
+	return s.sorted[0]
+}
+
+func (s durationStats) max() time.Duration {
+	if len(s.sorted) == 0 {
+		return 0
+	}
+	return s.sorted[len(s.sorted)-1]
+}
+
+func (s durationStats) percentile(percentile float64) time.Duration {
+	return durationPercentile(s.sorted, percentile)
+}
+
+// histogram counts the durations per bucket of histogramBuckets
+func (s durationStats) histogram() []int {
+	counts := make([]int, len(histogramBuckets)+1)
+	for _, duration := range s.sorted {
+		bucket, _ := slices.BinarySearch(histogramBuckets, duration+1)
+		counts[bucket]++
+	}
+	return counts
+}
+
+// sortedDurations returns a duration of all results in ascending order, e.g. (*Result).GetDuration
+func sortedDurations(results []*Result, duration func(result *Result) time.Duration) []time.Duration {
+	durations := make([]time.Duration, 0, len(results))
+	for _, result := range results {
+		durations = append(durations, duration(result))
+	}
+	slices.Sort(durations)
+	return durations
+}
+
+// durationPercentile calculates the percentile of sorted durations with the nearest-rank method
+func durationPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	rank = max(1, min(rank, len(sorted)))
+	return sorted[rank-1]
+}
+
+// slowestResults returns the n results with the longest response times
+func slowestResults(results []*Result, n int) []*Result {
+	slowest := slices.Clone(results)
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return slowest[i].GetDuration() > slowest[j].GetDuration()
+	})
+	return slowest[:min(n, len(slowest))]
+}
+
+// groupedDurationStats calculates the statistics per group, e.g. per host, sorted by the group names
+func groupedDurationStats(results []*Result, group func(result *Result) string) ([]string, map[string]durationStats) {
+	durations := map[string][]time.Duration{}
+	for _, result := range results {
+		name := group(result)
+		durations[name] = append(durations[name], result.GetDuration())
+	}
+
+	names := make([]string, 0, len(durations))
+	stats := make(map[string]durationStats, len(durations))
+	for name, groupDurations := range durations {
+		names = append(names, name)
+		stats[name] = newDurationStats(groupDurations)
+	}
+	sort.Strings(names)
+	return names, stats
+}
+
+// mediaType returns the content type without parameters, e.g. "text/html" of "text/html; charset=utf-8"
+func mediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return "(none)"
+	}
+	return mediaType
+}
+
+func printDurationStats(out io.Writer, stats durationStats) {
+	fmt.Fprintln(out, "Duration:            ", stats.total.Round(time.Millisecond))
+	fmt.Fprintln(out, "  - Min:             ", stats.min().Round(time.Millisecond))
+	fmt.Fprintln(out, "  - Max:             ", stats.max().Round(time.Millisecond))
+	fmt.Fprintln(out, "  - Avg:             ", stats.avg.Round(time.Millisecond))
+	fmt.Fprintln(out, "  - Std dev:         ", stats.stdDev.Round(time.Millisecond))
+	for _, percentile := range statisticsPercentiles {
+		fmt.Fprintf(out, "  - p%-16s %s\n", fmt.Sprintf("%g:", percentile), stats.percentile(percentile).Round(time.Millisecond))
+	}
+}
+
+func printHistogram(out io.Writer, stats durationStats) {
+	counts := stats.histogram()
+	maxCount := slices.Max(counts)
+
+	fmt.Fprintln(out, "Histogram:")
+	for i, count := range counts {
+		label := fmt.Sprintf(">= %s", histogramBuckets[len(histogramBuckets)-1])
+		if i < len(histogramBuckets) {
+			label = fmt.Sprintf("< %s", histogramBuckets[i])
+		}
+
+		bar := ""
+		if maxCount > 0 {
+			bar = strings.Repeat("#", int(math.Ceil(float64(count)*histogramBarWidth/float64(maxCount))))
+		}
+		fmt.Fprintln(out, strings.TrimRight(fmt.Sprintf("  - %-9s %6d %s", label, count, bar), " "))
+	}
+}
+
+func printSlowestResults(out io.Writer, results []*Result, n int) {
+	slowest := slowestResults(results, n)
+	if len(slowest) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "Slowest urls:\n")
+	for _, result := range slowest {
+		row := fmt.Sprintf("  - %6s %s", result.GetDuration().Round(time.Millisecond), result.url)
+		if result.foundOnUrl != "" {
+			row += " (found on " + result.foundOnUrl + ")"
+		}
+		fmt.Fprintln(out, row)
+	}
+}
+
+func printGroupedDurationStats(out io.Writer, title string, names []string, stats map[string]durationStats) {
+	fmt.Fprintf(out, "%-*s %8s %8s %8s %8s %8s %8s\n", statisticsGroupWidth+4, title, "Requests", "Avg", "p50", "p90", "p99", "Max")
+	for _, name := range names {
+		groupStats := stats[name]
+		if len(name) > statisticsGroupWidth {
+			name = name[:statisticsGroupWidth-3] + "..."
+		}
+		fmt.Fprintf(out, "  - %-*s %8d %8s %8s %8s %8s %8s\n",
+			statisticsGroupWidth,
+			name,
+			groupStats.count(),
+			groupStats.avg.Round(time.Millisecond),
+			groupStats.percentile(50).Round(time.Millisecond),
+			groupStats.percentile(90).Round(time.Millisecond),
+			groupStats.percentile(99).Round(time.Millisecond),
+			groupStats.max().Round(time.Millisecond),
+		)
+	}
+}
+
+// printStatistics prints the response times per host and content type and the slowest urls
+func printStatistics(out io.Writer, results []*Result, slowestCount int) {
+	hosts, hostStats := groupedDurationStats(results, func(result *Result) string {
+		return result.urlHost
+	})
+	printGroupedDurationStats(out, "Hosts:", hosts, hostStats)
+
+	contentTypes, contentTypeStats := groupedDurationStats(results, func(result *Result) string {
+		return mediaType(result.contentType)
+	})
+	printGroupedDurationStats(out, "Content types:", contentTypes, contentTypeStats)
+
+	if slowestCount > 0 {
+		printSlowestResults(out, results, slowestCount)
+	}
+}
@@ -0,0 +1,89 @@
+package grawl
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDurationPercentile(t *testing.T) {
+	durations := make([]time.Duration, 0, 10)
+	for i := 1; i <= 10; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		name       string
+		sorted     []time.Duration
+		percentile float64
+		expected   time.Duration
+	}{
+		{name: "median", sorted: durations, percentile: 50, expected: 5 * time.Millisecond},
+		{name: "nearest rank above", sorted: durations, percentile: 75, expected: 8 * time.Millisecond},
+		{name: "p90", sorted: durations, percentile: 90, expected: 9 * time.Millisecond},
+		{name: "p99", sorted: durations, percentile: 99, expected: 10 * time.Millisecond},
+		{name: "p100", sorted: durations, percentile: 100, expected: 10 * time.Millisecond},
+		{name: "p0", sorted: durations, percentile: 0, expected: time.Millisecond},
+		{name: "single duration", sorted: []time.Duration{time.Second}, percentile: 99, expected: time.Second},
+		{name: "no durations", sorted: nil, percentile: 50, expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if percentile := durationPercentile(test.sorted, test.percentile); percentile != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, percentile)
+			}
+		})
+	}
+}
+
+func TestDurationStatsHistogram(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		expected  []int
+	}{
+		{
+			name:      "below the first bound",
+			durations: []time.Duration{0, 10*time.Millisecond - 1},
+			expected:  []int{2, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:      "upper bounds are excluded",
+			durations: []time.Duration{10 * time.Millisecond, 50 * time.Millisecond, time.Second},
+			expected:  []int{0, 1, 1, 0, 0, 0, 1, 0, 0},
+		},
+		{
+			name:      "last bucket without upper bound",
+			durations: []time.Duration{5*time.Second - 1, 5 * time.Second, time.Minute},
+			expected:  []int{0, 0, 0, 0, 0, 0, 0, 1, 2},
+		},
+		{
+			name:      "no durations",
+			durations: nil,
+			expected:  []int{0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if counts := newDurationStats(test.durations).histogram(); !slices.Equal(counts, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, counts)
+			}
+		})
+	}
+}
+
+func TestNewDurationStats(t *testing.T) {
+	stats := newDurationStats([]time.Duration{4 * time.Millisecond, 2 * time.Millisecond, 6 * time.Millisecond})
+
+	if stats.min() != 2*time.Millisecond || stats.max() != 6*time.Millisecond {
+		t.Errorf("expected min 2ms and max 6ms, got %s and %s", stats.min(), stats.max())
+	}
+	if stats.avg != 4*time.Millisecond {
+		t.Errorf("expected avg 4ms, got %s", stats.avg)
+	}
+	if expected := time.Duration(1632993); stats.stdDev != expected {
+		t.Errorf("expected std dev %s, got %s", expected, stats.stdDev)
+	}
+}
@@ -0,0 +1,193 @@
+package grawl
+
+import (
+	"fmt"
+	"github.com/mattn/go-isatty"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const statusBarInterval = 250 * time.Millisecond
+
+// statusBar is a live footer in the last line of the terminal. The result rows scroll above it in a scroll
+// region, so other output does not need to know about the footer.
+type statusBar struct {
+	grawler     *Grawler
+	startedAt   time.Time
+	errors4xx   atomic.Uint32
+	errors5xx   atomic.Uint32
+	otherErrors atomic.Uint32
+	depth       atomic.Int32
+	rows        int
+	hidden      bool
+	mutex       sync.Mutex
+}
+
+// startStatusBar shows the status bar if stdout is a terminal and returns the function to remove it
+func (g *Grawler) startStatusBar() func() {
+	if !isatty.IsTerminal(os.Stdout.Fd()) {
+		return func() {}
+	}
+
+	if _, rows, ok := terminalSize(); !ok || rows < 3 {
+		return func() {}
+	}
+
+	bar := &statusBar{grawler: g, startedAt: time.Now()}
+	g.statusBar = bar
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(statusBarInterval)
+		defer ticker.Stop()
+
+		for {
+			bar.draw()
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		bar.remove()
+	}
+}
+
+// add counts a finished result
+func (s *statusBar) add(result *Result) {
+	s.depth.Store(int32(result.depth))
+
+	if counter := s.errorCounter(result); counter != nil {
+		counter.Add(1)
+	}
+}
+
+// forget removes the error of a result which is requested again
+func (s *statusBar) forget(result *Result) {
+	if counter := s.errorCounter(result); counter != nil {
+		counter.Add(^uint32(0))
+	}
+}
+
+// errorCounter returns the counter of the error class of a result, or nil if the result has no error
+func (s *statusBar) errorCounter(result *Result) *atomic.Uint32 {
+	switch {
+	case !result.HasError():
+		return nil
+	case result.statusCode >= 400 && result.statusCode < 500:
+		return &s.errors4xx
+	case result.statusCode >= 500 && result.statusCode < 600:
+		return &s.errors5xx
+	default:
+		return &s.otherErrors
+	}
+}
+
+func (s *statusBar) line(width int) string {
+	elapsed := time.Since(s.startedAt)
+	done := int(s.grawler.responseCount.Load())
+	inFlight := max(0, int(s.grawler.requestCount.Load())-done)
+	queued := max(0, s.grawler.runningRequests.QueuedCount()-inFlight)
+
+	perSecond := 0.0
+	if elapsed > 0 {
+		perSecond = float64(done) / elapsed.Seconds()
+	}
+
+	eta := "-"
+	if perSecond > 0 {
+		eta = time.Duration(float64(queued+inFlight) / perSecond * float64(time.Second)).Round(time.Second).String()
+	}
+
+	line := fmt.Sprintf(
+		" Done %d | In flight %d | Queued %d | %.1f req/s | Errors 4xx %d, 5xx %d, other %d | Depth %d | Elapsed %s | ETA %s",
+		done,
+		inFlight,
+		queued,
+		perSecond,
+		s.errors4xx.Load(),
+		s.errors5xx.Load(),
+		s.otherErrors.Load(),
+		s.depth.Load(),
+		elapsed.Round(time.Second),
+		eta,
+	)
+
+	if len(line) > width {
+		line = line[:max(0, width-1)]
+	}
+	return line + strings.Repeat(" ", max(0, width-len(line)))
+}
+
+// draw renders the footer in a single write, so it is not interleaved with the rows of other goroutines
+func (s *statusBar) draw() {
+	width, rows, ok := terminalSize()
+	if !ok {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.hidden {
+		return
+	}
+
+	var output strings.Builder
+	if rows != s.rows {
+		if s.rows == 0 {
+			// Make room for the footer, setting the scroll region moves the cursor to the top
+			output.WriteString("\n")
+		}
+		output.WriteString("\0337")
+		fmt.Fprintf(&output, "\033[1;%dr", rows-1)
+		output.WriteString("\0338")
+		if s.rows == 0 {
+			fmt.Fprintf(&output, "\033[%d;1H", rows-1)
+		}
+		s.rows = rows
+	}
+
+	output.WriteString("\0337")
+	fmt.Fprintf(&output, "\033[%d;1H\033[7m%s\033[0m", rows, s.line(width))
+	output.WriteString("\0338")
+
+	_, _ = os.Stdout.WriteString(output.String())
+}
+
+// remove resets the scroll region and clears the footer
+func (s *statusBar) remove() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.rows == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "\0337\033[r\033[%d;1H\033[2K\0338", s.rows)
+	s.rows = 0
+}
+
+// hide removes the footer until show is called, e.g. while a prompt waits for input below the last row
+func (s *statusBar) hide() {
+	s.mutex.Lock()
+	s.hidden = true
+	s.mutex.Unlock()
+	s.remove()
+}
+
+// show draws the footer again with the next tick
+func (s *statusBar) show() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.hidden = false
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package grawl
+
+// terminalSize is not supported, the status bar is not shown
+func terminalSize() (int, int, bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,95 @@
+package grawl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusBarErrorCounter(t *testing.T) {
+	errorCodeRanges, err := newResponseCodeRanges(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		err           error
+		expected4xx   uint32
+		expected5xx   uint32
+		expectedOther uint32
+	}{
+		{name: "ok", statusCode: 200},
+		{name: "not found", statusCode: 404, expected4xx: 1},
+		{name: "server error", statusCode: 503, expected5xx: 1},
+		{name: "request error", err: fmt.Errorf("connection refused"), expectedOther: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bar := &statusBar{}
+			result := &Result{statusCode: test.statusCode, error: test.err, depth: 3, httpErrorCodeRanges: errorCodeRanges}
+
+			bar.add(result)
+			if bar.errors4xx.Load() != test.expected4xx || bar.errors5xx.Load() != test.expected5xx || bar.otherErrors.Load() != test.expectedOther {
+				t.Errorf("expected errors %d, %d and %d, got %d, %d and %d",
+					test.expected4xx, test.expected5xx, test.expectedOther, bar.errors4xx.Load(), bar.errors5xx.Load(), bar.otherErrors.Load())
+			}
+			if bar.depth.Load() != 3 {
+				t.Errorf("expected depth 3, got %d", bar.depth.Load())
+			}
+
+			bar.forget(result)
+			if bar.errors4xx.Load() != 0 || bar.errors5xx.Load() != 0 || bar.otherErrors.Load() != 0 {
+				t.Errorf("expected no errors after forgetting the result, got %d, %d and %d",
+					bar.errors4xx.Load(), bar.errors5xx.Load(), bar.otherErrors.Load())
+			}
+		})
+	}
+}
+
+func TestStatusBarLine(t *testing.T) {
+	expected := " Done 20 | In flight 2 | Queued 4 | 2.0 req/s | Errors 4xx 1, 5xx 0, other 0 | Depth 2 | Elapsed 10s | ETA 3s"
+
+	tests := []struct {
+		name     string
+		width    int
+		expected string
+	}{
+		{name: "padded to the width", width: len(expected) + 5, expected: expected + "     "},
+		{name: "cut to the width", width: 20, expected: expected[:19] + " "},
+		{name: "no width", width: 0, expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := &Grawler{runningRequests: NewRunningRequests()}
+			g.requestCount.Store(22)
+			g.responseCount.Store(20)
+			for i := range 6 {
+				g.runningRequests.AddQueuedUrl(fmt.Sprintf("https://example.com/%d", i), 1, "https://example.com/", false)
+			}
+
+			bar := &statusBar{grawler: g, startedAt: time.Now().Add(-10 * time.Second)}
+			bar.errors4xx.Store(1)
+			bar.depth.Store(2)
+
+			if line := bar.line(test.width); line != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, line)
+			}
+		})
+	}
+}
+
+func TestStatusBarLineWithoutResponses(t *testing.T) {
+	g := &Grawler{runningRequests: NewRunningRequests()}
+	g.requestCount.Store(1)
+	bar := &statusBar{grawler: g, startedAt: time.Now()}
+
+	line := bar.line(200)
+	if !strings.Contains(line, "In flight 1 | Queued 0 | 0.0 req/s") || !strings.Contains(line, "ETA -") {
+		t.Errorf("expected no rate and eta, got %q", line)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package grawl
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+)
+
+// terminalSize returns the columns and rows of the terminal of stdout
+func terminalSize() (int, int, bool) {
+	size, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 || size.Row == 0 {
+		return 0, 0, false
+	}
+	return int(size.Col), int(size.Row), true
+}
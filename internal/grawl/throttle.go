@@ -0,0 +1,305 @@
+package grawl
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	throttleReasonTooManyRequests = "429"
+	throttleReasonUnavailable     = "503"
+	throttleReasonSlow            = "slow"
+	throttleReasonError           = "error"
+
+	defaultAdaptiveMaxDelay = 30 * time.Second
+
+	// throttleMinDelay is the first delay after a back off, it is doubled on each further back off
+	throttleMinDelay = 100 * time.Millisecond
+	// throttleBackOffInterval prevents the responses of parallel requests from backing off all at once
+	throttleBackOffInterval = time.Second
+	// throttleRecoverAfter is the number of healthy responses in a row after which the throttling is reduced
+	throttleRecoverAfter = 10
+	// throttleSlowFactor marks responses which take longer than the average times this factor as slow
+	throttleSlowFactor = 3
+	throttleMinSamples = 5
+)
+
+// adaptiveThrottle slows down the requests per host on 429 and 503 responses, errors and slow responses.
+// It reduces the parallel requests and increases the delay, and recovers gradually on healthy responses.
+type adaptiveThrottle struct {
+	hosts      sync.Map
+	limitRules []LimitRule
+	maxDelay   time.Duration
+	hostsMutex sync.Mutex
+	output     func() io.Writer
+}
+
+type hostThrottle struct {
+	host          string
+	mutex         sync.Mutex
+	slotFreed     *sync.Cond
+	running       int
+	parallel      int
+	maxParallel   int
+	delay         time.Duration
+	maxDelay      time.Duration
+	nextRequestAt time.Time
+	lastBackOffAt time.Time
+	avgDuration   time.Duration
+	samples       int
+	healthy       int
+	backOffs      map[string]int
+	retryAfters   int
+	highestDelay  time.Duration
+	lowestLimit   int
+	output        func() io.Writer
+}
+
+// newAdaptiveThrottle creates the throttle, the changes of the throttling are printed to the writer of output
+func newAdaptiveThrottle(limitRules []LimitRule, maxDelay time.Duration, output func() io.Writer) *adaptiveThrottle {
+	if maxDelay <= 0 {
+		maxDelay = defaultAdaptiveMaxDelay
+	}
+	return &adaptiveThrottle{limitRules: limitRules, maxDelay: maxDelay, output: output}
+}
+
+// host returns the throttle of a host, it starts with the parallel requests of the matching limit rule
+func (a *adaptiveThrottle) host(host string) *hostThrottle {
+	if throttle, ok := a.hosts.Load(host); ok {
+		return throttle.(*hostThrottle)
+	}
+
+	a.hostsMutex.Lock()
+	defer a.hostsMutex.Unlock()
+	if throttle, ok := a.hosts.Load(host); ok {
+		return throttle.(*hostThrottle)
+	}
+
+	parallel := max(1, valueOf(limitRuleOfHost(a.limitRules, host).Parallel))
+	throttle := &hostThrottle{
+		host:        host,
+		parallel:    parallel,
+		maxParallel: parallel,
+		maxDelay:    a.maxDelay,
+		backOffs:    map[string]int{},
+		lowestLimit: parallel,
+		output:      a.output,
+	}
+	throttle.slotFreed = sync.NewCond(&throttle.mutex)
+	a.hosts.Store(host, throttle)
+	return throttle
+}
+
+// limitRuleOfHost returns the first limit rule matching the host, the last rule matches all hosts
+func limitRuleOfHost(rules []LimitRule, host string) LimitRule {
+	for _, rule := range rules {
+		if rule.matches(host) {
+			return rule
+		}
+	}
+	return LimitRule{DomainGlob: defaultLimitDomainGlob}
+}
+
+// stop wakes up the requests waiting for a free slot, so they see that the grawling stopped
+func (a *adaptiveThrottle) stop() {
+	a.hosts.Range(func(_, value any) bool {
+		throttle := value.(*hostThrottle)
+		// With the lock a waiting request either has seen the stop already or is woken up
+		throttle.mutex.Lock()
+		throttle.slotFreed.Broadcast()
+		throttle.mutex.Unlock()
+		return true
+	})
+}
+
+// acquire waits for a free slot and the delay of the host. It returns false if the grawling stopped meanwhile.
+func (t *hostThrottle) acquire(stopped <-chan struct{}) bool {
+	t.mutex.Lock()
+	for t.running >= t.parallel {
+		select {
+		case <-stopped:
+			t.mutex.Unlock()
+			return false
+		default:
+		}
+		t.slotFreed.Wait()
+	}
+	t.running++
+	start := time.Now()
+	if t.nextRequestAt.After(start) {
+		start = t.nextRequestAt
+	}
+	t.nextRequestAt = start.Add(t.delay)
+	t.mutex.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return true
+	}
+
+	select {
+	case <-time.After(wait):
+		return true
+	case <-stopped:
+		t.release()
+		return false
+	}
+}
+
+func (t *hostThrottle) release() {
+	t.mutex.Lock()
+	t.running--
+	t.mutex.Unlock()
+	t.slotFreed.Broadcast()
+}
+
+// update releases the slot of a request and adapts the throttling to its response
+func (t *hostThrottle) update(res *http.Response, err error, duration time.Duration) {
+	defer t.release()
+	t.adapt(res, err, duration)
+}
+
+// adapt adapts the throttling to a response without releasing a slot, e.g. to the response of a redirect hop
+func (t *hostThrottle) adapt(res *http.Response, err error, duration time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	switch {
+	case err != nil:
+		t.backOff(throttleReasonError)
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable:
+		if retryAfter := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); retryAfter > 0 {
+			retryAt := time.Now().Add(min(retryAfter, t.maxDelay))
+			if retryAt.After(t.nextRequestAt) {
+				t.nextRequestAt = retryAt
+			}
+			t.retryAfters++
+		}
+		t.backOff(strconv.Itoa(res.StatusCode))
+	case t.samples >= throttleMinSamples && duration > t.avgDuration*throttleSlowFactor:
+		t.addSample(duration)
+		t.backOff(throttleReasonSlow)
+	default:
+		t.addSample(duration)
+		t.healthy++
+		if t.healthy >= throttleRecoverAfter {
+			t.healthy = 0
+			t.recover()
+		}
+	}
+}
+
+// addSample updates the moving average of the response durations, so the average follows a lasting slowdown
+func (t *hostThrottle) addSample(duration time.Duration) {
+	if t.samples == 0 {
+		t.avgDuration = duration
+	} else {
+		t.avgDuration += (duration - t.avgDuration) / 5
+	}
+	t.samples++
+}
+
+// backOff halves the parallel requests and doubles the delay. Must be called with the mutex locked.
+func (t *hostThrottle) backOff(reason string) {
+	t.backOffs[reason]++
+	t.healthy = 0
+
+	now := time.Now()
+	if now.Sub(t.lastBackOffAt) < throttleBackOffInterval {
+		return
+	}
+	t.lastBackOffAt = now
+
+	t.parallel = max(1, t.parallel/2)
+	t.delay = min(max(t.delay*2, throttleMinDelay), t.maxDelay)
+	t.highestDelay = max(t.highestDelay, t.delay)
+	t.lowestLimit = min(t.lowestLimit, t.parallel)
+
+	color.New(color.FgYellow).Fprintf(t.output(), "Throttling %s (%s): %d parallel, %s delay\n", t.host, reason, t.parallel, t.delay)
+}
+
+// recover reduces the delay first and then increases the parallel requests. Must be called with the mutex locked.
+func (t *hostThrottle) recover() {
+	if t.delay == 0 && t.parallel == t.maxParallel {
+		return
+	}
+
+	if t.delay > 0 {
+		t.delay /= 2
+		if t.delay < throttleMinDelay {
+			t.delay = 0
+		}
+	} else {
+		t.parallel++
+	}
+
+	if t.delay == 0 && t.parallel == t.maxParallel {
+		color.New(color.FgGreen).Fprintf(t.output(), "Throttling of %s recovered\n", t.host)
+	}
+}
+
+// parseRetryAfter parses the seconds or the http date of a Retry-After header
+func parseRetryAfter(retryAfter string, now time.Time) time.Duration {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if retryAfter == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil {
+		return time.Duration(max(0, seconds)) * time.Second
+	}
+
+	if date, err := http.ParseTime(retryAfter); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+func (a *adaptiveThrottle) printSummary(out io.Writer) {
+	throttles := make([]*hostThrottle, 0)
+	a.hosts.Range(func(_, value any) bool {
+		throttles = append(throttles, value.(*hostThrottle))
+		return true
+	})
+	sort.Slice(throttles, func(i, j int) bool {
+		return throttles[i].host < throttles[j].host
+	})
+
+	total := 0
+	lines := make([]string, 0)
+	for _, t := range throttles {
+		t.mutex.Lock()
+		backOffs := 0
+		for _, count := range t.backOffs {
+			backOffs += count
+		}
+		if backOffs > 0 {
+			lines = append(lines, fmt.Sprintf(
+				"  - %s: %d (429: %d, 503: %d, slow: %d, errors: %d, Retry-After: %d, max delay: %s, min parallel: %d)",
+				t.host,
+				backOffs,
+				t.backOffs[throttleReasonTooManyRequests],
+				t.backOffs[throttleReasonUnavailable],
+				t.backOffs[throttleReasonSlow],
+				t.backOffs[throttleReasonError],
+				t.retryAfters,
+				t.highestDelay,
+				t.lowestLimit,
+			))
+		}
+		total += backOffs
+		t.mutex.Unlock()
+	}
+
+	fmt.Fprintf(out, "Throttled responses:  %d\n", total)
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+}
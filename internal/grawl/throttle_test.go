@@ -0,0 +1,200 @@
+package grawl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		expected   time.Duration
+	}{
+		{name: "seconds", retryAfter: "120", expected: 2 * time.Minute},
+		{name: "seconds with whitespace", retryAfter: " 5 ", expected: 5 * time.Second},
+		{name: "negative seconds", retryAfter: "-5", expected: 0},
+		{name: "http date", retryAfter: "Mon, 01 Jan 2024 10:00:30 GMT", expected: 30 * time.Second},
+		{name: "http date in the past", retryAfter: "Mon, 01 Jan 2024 09:59:00 GMT", expected: 0},
+		{name: "invalid", retryAfter: "soon", expected: 0},
+		{name: "empty", retryAfter: "", expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if retryAfter := parseRetryAfter(test.retryAfter, now); retryAfter != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, retryAfter)
+			}
+		})
+	}
+}
+
+// newTestThrottle returns a throttle with 4 parallel requests per host and a max delay of 1s
+func newTestThrottle(t *testing.T) *adaptiveThrottle {
+	t.Helper()
+
+	limitRules, err := EffectiveLimitRules(Flags{FlagParallel: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newAdaptiveThrottle(limitRules, time.Second, func() io.Writer { return io.Discard })
+}
+
+func TestHostThrottleBackOffAndRecover(t *testing.T) {
+	tests := []struct {
+		name             string
+		steps            []string
+		expectedParallel int
+		expectedDelay    time.Duration
+	}{
+		{
+			name:             "back off",
+			steps:            []string{"back off"},
+			expectedParallel: 2,
+			expectedDelay:    100 * time.Millisecond,
+		},
+		{
+			name:             "back offs within the interval are counted once",
+			steps:            []string{"back off", "back off within interval", "back off within interval"},
+			expectedParallel: 2,
+			expectedDelay:    100 * time.Millisecond,
+		},
+		{
+			name:             "back off to the max delay",
+			steps:            []string{"back off", "back off", "back off", "back off", "back off", "back off"},
+			expectedParallel: 1,
+			expectedDelay:    time.Second,
+		},
+		{
+			name:             "recover the delay first",
+			steps:            []string{"back off", "back off", "recover"},
+			expectedParallel: 1,
+			expectedDelay:    100 * time.Millisecond,
+		},
+		{
+			name:             "recover the parallel requests after the delay",
+			steps:            []string{"back off", "back off", "recover", "recover", "recover"},
+			expectedParallel: 2,
+			expectedDelay:    0,
+		},
+		{
+			name:             "recover not above the limit",
+			steps:            []string{"back off", "recover", "recover", "recover", "recover"},
+			expectedParallel: 4,
+			expectedDelay:    0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			throttle := newTestThrottle(t).host("example.com")
+
+			throttle.mutex.Lock()
+			for _, step := range test.steps {
+				switch step {
+				case "back off":
+					throttle.lastBackOffAt = time.Time{}
+					throttle.backOff(throttleReasonError)
+				case "back off within interval":
+					throttle.backOff(throttleReasonError)
+				case "recover":
+					throttle.recover()
+				}
+			}
+			throttle.mutex.Unlock()
+
+			if throttle.parallel != test.expectedParallel {
+				t.Errorf("expected %d parallel, got %d", test.expectedParallel, throttle.parallel)
+			}
+			if throttle.delay != test.expectedDelay {
+				t.Errorf("expected %s delay, got %s", test.expectedDelay, throttle.delay)
+			}
+		})
+	}
+}
+
+func TestHostThrottleAcquireStopped(t *testing.T) {
+	adaptive := newTestThrottle(t)
+	throttle := adaptive.host("example.com")
+	stopped := make(chan struct{})
+
+	for i := 0; i < throttle.parallel; i++ {
+		if !throttle.acquire(stopped) {
+			t.Fatal("expected a free slot")
+		}
+	}
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- throttle.acquire(stopped)
+	}()
+
+	// Wait until the request waits for a free slot
+	time.Sleep(50 * time.Millisecond)
+	close(stopped)
+	adaptive.stop()
+
+	select {
+	case ok := <-acquired:
+		if ok {
+			t.Error("expected no slot after the stop")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the waiting request to wake up on stop")
+	}
+}
+
+func TestAdaptiveRetryAfterLongerThanRequestTimeout(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/busy">busy</a><a href="/a">a</a><a href="/b">b</a></body></html>`)
+	})
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	for _, page := range []string{"/a", "/b"} {
+		mux.HandleFunc(page, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html><body></body></html>")
+		})
+	}
+
+	grawler, err := NewGrawler(Flags{
+		FlagParallel:       1,
+		FlagAdaptive:       true,
+		FlagRequestTimeout: 0.5,
+		FlagFailThreshold:  "0",
+		FlagNoStatusBar:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grawler.SetOutput(io.Discard)
+
+	if exitCode := grawler.Grawl(server.URL + "/"); exitCode != ExitCodeOk {
+		t.Fatalf("expected exit code %d, got %d", ExitCodeOk, exitCode)
+	}
+
+	// The requests waiting for the Retry-After of /busy must not time out
+	errors := make([]string, 0)
+	for _, result := range *grawler.runningRequests.GetValues() {
+		if result.HasError() {
+			errors = append(errors, result.urlPath)
+		}
+	}
+	if !slices.Equal(errors, []string{"/busy"}) {
+		t.Errorf("expected only /busy to fail, got %v", errors)
+	}
+}
@@ -0,0 +1,151 @@
+package grawl
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// timingPhases are the phases of a request in the order they are printed. The connection phases only happen for
+// requests which opened a new connection.
+var timingPhases = []struct {
+	name       string
+	connection bool
+	duration   func(t requestTiming) time.Duration
+}{
+	{"DNS", true, func(t requestTiming) time.Duration { return t.dns }},
+	{"Connect", true, func(t requestTiming) time.Duration { return t.connect }},
+	{"TLS", true, func(t requestTiming) time.Duration { return t.tls }},
+	{"TTFB", false, func(t requestTiming) time.Duration { return t.ttfb }},
+	{"Download", false, func(t requestTiming) time.Duration { return t.download }},
+}
+
+// requestTiming is the breakdown of the duration of a request. The phases of all requests of a redirect chain are
+// added up. DNS, connect and TLS are 0 if a connection has been reused.
+type requestTiming struct {
+	dns     time.Duration
+	connect time.Duration
+	tls     time.Duration
+	// ttfb is the time from sending the request to the first byte of the response, i.e. the time of the server
+	ttfb     time.Duration
+	download time.Duration
+	// newConnection is true if a round trip opened a new connection instead of reusing one
+	newConnection bool
+}
+
+func (t *requestTiming) add(other requestTiming) {
+	t.newConnection = t.newConnection || other.newConnection
+	t.dns += other.dns
+	t.connect += other.connect
+	t.tls += other.tls
+	t.ttfb += other.ttfb
+	t.download += other.download
+}
+
+// hasConnectionPhases tells if a connection has been opened, for timings read from a results file. Connections which
+// opened in less than a millisecond are taken as reused.
+func (t *requestTiming) hasConnectionPhases() bool {
+	return t.dns > 0 || t.connect > 0 || t.tls > 0
+}
+
+// roundTripTrace records the timestamps of a single http round trip
+type roundTripTrace struct {
+	sync.Mutex
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	gotConn      bool
+	reused       bool
+}
+
+// withTrace adds a client trace to the request
+func (rt *roundTripTrace) withTrace(req *http.Request) *http.Request {
+	stamp := func(t *time.Time) {
+		rt.Lock()
+		*t = time.Now()
+		rt.Unlock()
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { stamp(&rt.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { stamp(&rt.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Only the first of multiple connection attempts is the start, e.g. for ipv6 and ipv4
+			rt.Lock()
+			if rt.connectStart.IsZero() {
+				rt.connectStart = time.Now()
+			}
+			rt.Unlock()
+		},
+		ConnectDone: func(_ string, _ string, err error) {
+			if err == nil {
+				stamp(&rt.connectDone)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			rt.Lock()
+			rt.gotConn = true
+			rt.reused = info.Reused
+			rt.Unlock()
+		},
+		TLSHandshakeStart:    func() { stamp(&rt.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { stamp(&rt.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { stamp(&rt.wroteRequest) },
+		GotFirstResponseByte: func() { stamp(&rt.firstByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// timing returns the durations of the phases up to the first byte. Phases without both timestamps are 0.
+func (rt *roundTripTrace) timing() requestTiming {
+	rt.Lock()
+	defer rt.Unlock()
+
+	return requestTiming{
+		dns:     durationBetween(rt.dnsStart, rt.dnsDone),
+		connect: durationBetween(rt.connectStart, rt.connectDone),
+		tls:     durationBetween(rt.tlsStart, rt.tlsDone),
+		ttfb:    durationBetween(rt.wroteRequest, rt.firstByte),
+
+		newConnection: rt.gotConn && !rt.reused,
+	}
+}
+
+// downloadTiming returns the time since the first byte of the response
+func (rt *roundTripTrace) downloadTiming() requestTiming {
+	rt.Lock()
+	defer rt.Unlock()
+
+	return requestTiming{download: durationBetween(rt.firstByte, time.Now())}
+}
+
+func durationBetween(start time.Time, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// timedBody records the time when the body has been read completely. Aborted downloads have no download time.
+type timedBody struct {
+	io.ReadCloser
+	onDownloaded func()
+	once         sync.Once
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if errors.Is(err, io.EOF) {
+		b.once.Do(b.onDownloaded)
+	}
+	return n, err
+}
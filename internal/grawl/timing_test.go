@@ -0,0 +1,201 @@
+package grawl
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestTimingAdd(t *testing.T) {
+	tests := []struct {
+		name     string
+		timings  []requestTiming
+		expected requestTiming
+	}{
+		{
+			name:     "single round trip",
+			timings:  []requestTiming{{dns: 1, connect: 2, tls: 3, ttfb: 4, download: 5, newConnection: true}},
+			expected: requestTiming{dns: 1, connect: 2, tls: 3, ttfb: 4, download: 5, newConnection: true},
+		},
+		{
+			name: "redirect chain",
+			timings: []requestTiming{
+				{dns: 1, connect: 2, tls: 3, ttfb: 4, newConnection: true},
+				{ttfb: 10, download: 20},
+			},
+			expected: requestTiming{dns: 1, connect: 2, tls: 3, ttfb: 14, download: 20, newConnection: true},
+		},
+		{
+			name:     "reused connections",
+			timings:  []requestTiming{{ttfb: 4}, {ttfb: 6}},
+			expected: requestTiming{ttfb: 10},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timing := requestTiming{}
+			for _, other := range test.timings {
+				timing.add(other)
+			}
+			if timing != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, timing)
+			}
+		})
+	}
+}
+
+func TestRequestTimingHasConnectionPhases(t *testing.T) {
+	tests := []struct {
+		name     string
+		timing   requestTiming
+		expected bool
+	}{
+		{name: "no phases", timing: requestTiming{}, expected: false},
+		{name: "server phases only", timing: requestTiming{ttfb: time.Second, download: time.Second}, expected: false},
+		{name: "dns", timing: requestTiming{dns: time.Millisecond}, expected: true},
+		{name: "connect", timing: requestTiming{connect: time.Millisecond}, expected: true},
+		{name: "tls", timing: requestTiming{tls: time.Millisecond}, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.timing.hasConnectionPhases() != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, !test.expected)
+			}
+		})
+	}
+}
+
+func TestDurationBetween(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      time.Time
+		expected time.Duration
+	}{
+		{name: "both timestamps", start: start, end: start.Add(time.Second), expected: time.Second},
+		{name: "missing start", end: start, expected: 0},
+		{name: "missing end", start: start, expected: 0},
+		{name: "end before start", start: start, end: start.Add(-time.Second), expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if duration := durationBetween(test.start, test.end); duration != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, duration)
+			}
+		})
+	}
+}
+
+func TestRoundTripTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(strings.Repeat("a", 1024)))
+	}))
+	defer server.Close()
+
+	transport := &http.Transport{}
+	defer transport.CloseIdleConnections()
+
+	tests := []struct {
+		name                  string
+		expectedNewConnection bool
+	}{
+		{name: "new connection", expectedNewConnection: true},
+		{name: "reused connection", expectedNewConnection: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			trace := &roundTripTrace{}
+			res, err := transport.RoundTrip(trace.withTrace(req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			downloaded := 0
+			res.Body = &timedBody{ReadCloser: res.Body, onDownloaded: func() { downloaded++ }}
+			if _, err = io.ReadAll(res.Body); err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Read(make([]byte, 1))
+			res.Body.Close()
+
+			timing := trace.timing()
+			if timing.newConnection != test.expectedNewConnection {
+				t.Errorf("expected a new connection %v, got %v", test.expectedNewConnection, timing.newConnection)
+			}
+			if !test.expectedNewConnection && timing.hasConnectionPhases() {
+				t.Errorf("expected no connection phases of a reused connection, got %+v", timing)
+			}
+			if timing.ttfb < 20*time.Millisecond {
+				t.Errorf("expected a ttfb of at least 20ms, got %v", timing.ttfb)
+			}
+			if downloaded != 1 {
+				t.Errorf("expected the download to be recorded once, got %d", downloaded)
+			}
+		})
+	}
+}
+
+func TestTimedBodyAborted(t *testing.T) {
+	downloaded := false
+	body := &timedBody{ReadCloser: io.NopCloser(strings.NewReader("abc")), onDownloaded: func() { downloaded = true }}
+
+	if _, err := body.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+
+	if downloaded {
+		t.Error("expected an aborted download not to be recorded")
+	}
+}
+
+func TestGrawlTimings(t *testing.T) {
+	server := newCoverageTestServer(t, false, "/page")
+
+	grawler, err := NewGrawler(Flags{FlagParallel: 1, FlagRequestTimeout: 5, FlagNoStatusBar: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := &syncBuffer{}
+	grawler.SetOutput(output)
+
+	if err = grawler.Crawl(context.Background(), server.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+	grawler.printSummary()
+
+	for _, phase := range timingPhases {
+		if expected := "  - " + phase.name + ":"; !strings.Contains(output.String(), expected) {
+			t.Errorf("expected %q in the output, got %s", expected, output)
+		}
+	}
+
+	newConnections := 0
+	for _, result := range *grawler.runningRequests.GetValues() {
+		if result.timing.ttfb <= 0 {
+			t.Errorf("expected a ttfb of %s, got %+v", result.url, result.timing)
+		}
+		if result.timing.newConnection {
+			newConnections++
+		}
+	}
+	if newConnections == 0 {
+		t.Error("expected a result with a new connection")
+	}
+}
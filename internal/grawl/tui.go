@@ -0,0 +1,635 @@
+package grawl
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-isatty"
+	"github.com/rivo/tview"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tuiRefreshInterval = 250 * time.Millisecond
+	tuiMaxResults      = 1000
+	tuiMaxRows         = 500
+	tuiMaxLogLines     = 500
+	tuiPageMain        = "main"
+	tuiPageDetails     = "details"
+	tuiHelp            = "[Tab] pane  [Enter] details  [p] pause/resume  [f] filter  [r] retry  [q] quit"
+)
+
+// tuiFilters are the status filters of the results pane, [f] selects the next one
+var tuiFilters = []struct {
+	name  string
+	match func(result *Result) bool
+}{
+	{"all", func(*Result) bool { return true }},
+	{"2xx", statusClassFilter(2)},
+	{"3xx", statusClassFilter(3)},
+	{"4xx", statusClassFilter(4)},
+	{"5xx", statusClassFilter(5)},
+	{"errors", (*Result).HasError},
+}
+
+func statusClassFilter(class int) func(result *Result) bool {
+	return func(result *Result) bool {
+		return result.statusCode/100 == class
+	}
+}
+
+// tui is the interactive terminal ui of --tui. All other output of the grawler is captured and shown in
+// the log pane, so it does not need to know about the tui.
+type tui struct {
+	grawler  *Grawler
+	app      *tview.Application
+	pages    *tview.Pages
+	results  *tuiTable
+	errors   *tuiTable
+	hosts    *tuiTable
+	frontier *tuiTable
+	log      *tview.TextView
+	status   *tview.TextView
+	panes    []*tuiTable
+	// detailsOf is the pane whose selected result is shown in the details
+	detailsOf *tuiTable
+
+	mutex    sync.Mutex
+	rows     []*Result
+	logLines []string
+	filter   int
+	finished bool
+	exited   bool
+	// rowsChanged tells refresh to rebuild the results pane, after a new result or another filter
+	rowsChanged bool
+	// requestsVersion is the version of the running requests shown in the other panes, they are only
+	// rebuilt when it has changed. It is only used by the event loop of the app.
+	requestsVersion uint64
+	requestsShown   bool
+
+	quit     chan struct{}
+	quitOnce sync.Once
+	done     chan struct{}
+
+	stdout      *os.File
+	colorOutput io.Writer
+	pipe        *os.File
+	captured    chan struct{}
+}
+
+// tuiTable is a table whose rows are identified by urls, so the selection survives a refresh
+type tuiTable struct {
+	*tview.Table
+	header []string
+	keys   []string
+}
+
+type tuiRow struct {
+	key   string
+	color tcell.Color
+	cells []string
+}
+
+func newTuiTable(title string, header ...string) *tuiTable {
+	table := &tuiTable{Table: tview.NewTable(), header: header}
+	table.SetFixed(1, 0).SetSelectable(true, false).SetBorder(true).SetTitle(" " + title + " ")
+	return table
+}
+
+// setRows replaces the rows and keeps the selected url selected. A selection on the first row stays
+// on the first row, so the newest rows stay visible.
+func (t *tuiTable) setRows(rows []tuiRow) {
+	selectedRow, _ := t.GetSelection()
+	selectedKey := t.selectedKey()
+
+	t.Clear()
+	for column, name := range t.header {
+		t.SetCell(0, column, tview.NewTableCell(name).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+
+	t.keys = make([]string, len(rows))
+	for i, row := range rows {
+		t.keys[i] = row.key
+		for column, text := range row.cells {
+			cell := tview.NewTableCell(tview.Escape(text)).SetTextColor(row.color)
+			if column == len(row.cells)-1 {
+				cell.SetExpansion(1)
+			}
+			t.SetCell(i+1, column, cell)
+		}
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+	if selectedRow > 1 {
+		for i, key := range t.keys {
+			if key == selectedKey {
+				t.Select(i+1, 0)
+				return
+			}
+		}
+	}
+	t.Select(max(1, min(selectedRow, len(rows))), 0)
+}
+
+func (t *tuiTable) selectedKey() string {
+	row, _ := t.GetSelection()
+	if row < 1 || row > len(t.keys) {
+		return ""
+	}
+	return t.keys[row-1]
+}
+
+// startTui starts the tui and captures the output. It returns the function to close the tui after the
+// grawling. Without a usable terminal the results are printed as usual.
+func (g *Grawler) startTui() func() {
+	// The keys are read from the terminal, the app initializes the screen
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		fmt.Fprintln(g.out(), "Could not start the tui: no terminal")
+		return func() {}
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		fmt.Fprintln(g.out(), "Could not start the tui:", err)
+		return func() {}
+	}
+
+	t := &tui{
+		grawler: g,
+		app:     tview.NewApplication().SetScreen(screen),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		// The headers of the results pane are set by the first refresh
+		rowsChanged: true,
+	}
+	t.layout()
+
+	if err = t.captureStdout(); err != nil {
+		screen.Fini()
+		fmt.Fprintln(g.out(), "Could not start the tui:", err)
+		return func() {}
+	}
+
+	g.tui = t
+	g.statusBar = &statusBar{grawler: g, startedAt: time.Now()}
+	t.refresh()
+
+	go func() {
+		if err := t.app.Run(); err != nil {
+			t.addLog(fmt.Sprintf("Tui error: %v", err))
+		}
+		t.mutex.Lock()
+		t.exited = true
+		t.mutex.Unlock()
+		close(t.done)
+	}()
+
+	// Only this goroutine updates and stops the app, so no update is queued to a stopped app
+	go func() {
+		ticker := time.NewTicker(tuiRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.app.QueueUpdateDraw(t.refresh)
+			case <-t.quit:
+				t.app.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		t.close(!g.isStopping())
+	}
+}
+
+// abortTui closes the tui without waiting for the user, e.g. if the grawling could not be started
+func (g *Grawler) abortTui() {
+	if g.tui != nil {
+		g.tui.close(false)
+	}
+}
+
+func (t *tui) layout() {
+	t.results = newTuiTable("Results", "Status", "", "Duration", "Url")
+	t.errors = newTuiTable("Errors", "Status", "Url", "Found on")
+	t.hosts = newTuiTable("Hosts", "Host", "Requests", "Errors", "Avg", "p90")
+	t.frontier = newTuiTable("Frontier", "State", "Depth", "Url")
+	t.panes = []*tuiTable{t.results, t.errors, t.hosts, t.frontier}
+
+	for _, pane := range []*tuiTable{t.results, t.errors} {
+		pane.SetSelectedFunc(func(int, int) {
+			t.showDetails(pane.selectedKey())
+		})
+	}
+
+	t.log = tview.NewTextView().SetDynamicColors(true).SetMaxLines(tuiMaxLogLines)
+	t.log.SetBorder(true).SetTitle(" Log ")
+	t.status = tview.NewTextView().SetDynamicColors(true)
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(t.results, 0, 3, true).
+			AddItem(t.hosts, 0, 2, false), 0, 3, true).
+		AddItem(tview.NewFlex().
+			AddItem(t.errors, 0, 3, false).
+			AddItem(t.frontier, 0, 2, false), 0, 2, false).
+		AddItem(t.log, 0, 1, false).
+		AddItem(t.status, 2, 0, false)
+
+	t.pages = tview.NewPages().AddPage(tuiPageMain, root, true, true)
+	t.app.SetRoot(t.pages, true).SetInputCapture(t.onKey)
+}
+
+// onKey handles the keys of the tui. Keys which are not handled are passed to the focused pane.
+func (t *tui) onKey(event *tcell.EventKey) *tcell.EventKey {
+	if name, _ := t.pages.GetFrontPage(); name == tuiPageDetails {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyEnter || event.Rune() == 'q':
+			t.closeDetails()
+			return nil
+		case event.Key() == tcell.KeyCtrlC:
+			t.stop()
+			return nil
+		}
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyCtrlC:
+		t.stop()
+		return nil
+	case tcell.KeyTab:
+		t.focusPane(1)
+		return nil
+	case tcell.KeyBacktab:
+		t.focusPane(-1)
+		return nil
+	}
+
+	switch event.Rune() {
+	case 'q':
+		t.stop()
+	case 'p':
+		t.togglePause()
+	case 'f':
+		t.mutex.Lock()
+		t.filter = (t.filter + 1) % len(tuiFilters)
+		t.rowsChanged = true
+		t.mutex.Unlock()
+		t.refresh()
+	case 'r':
+		if pane := t.focusedPane(); pane == t.results || pane == t.errors {
+			t.retry(pane.selectedKey())
+		}
+	default:
+		return event
+	}
+	return nil
+}
+
+func (t *tui) focusedPane() *tuiTable {
+	for _, pane := range t.panes {
+		if pane.HasFocus() {
+			return pane
+		}
+	}
+	return t.results
+}
+
+func (t *tui) focusPane(step int) {
+	for i, pane := range t.panes {
+		if pane.HasFocus() {
+			t.app.SetFocus(t.panes[(i+step+len(t.panes))%len(t.panes)])
+			return
+		}
+	}
+	t.app.SetFocus(t.results)
+}
+
+// stop stops the grawling, running requests are finished in the background
+func (t *tui) stop() {
+	t.mutex.Lock()
+	finished := t.finished
+	t.mutex.Unlock()
+
+	if !finished {
+		t.grawler.stop(ExitCodeInterrupted, "Grawling interrupted. Waiting for running requests to finish.")
+	}
+	t.quitOnce.Do(func() {
+		close(t.quit)
+	})
+}
+
+func (t *tui) togglePause() {
+	if t.grawler.pause.pause() {
+		fmt.Fprintln(t.grawler.out(), "Grawling paused. Running requests are finished, press p to resume.")
+	} else if t.grawler.pause.resume() {
+		fmt.Fprintln(t.grawler.out(), "Grawling resumed.")
+	}
+}
+
+// pauseOnError pauses the grawling without blocking, see --pause-on-error
+func (t *tui) pauseOnError(result *Result) {
+	if t.grawler.pause.pause() {
+		fmt.Fprintf(t.grawler.out(), "Grawling paused after error of %s. Press p to resume or r to retry a failed url.\n", result.url)
+	}
+}
+
+func (t *tui) retry(url string) {
+	if url == "" {
+		return
+	}
+	if err := t.grawler.retryFailedUrl(url); err != nil {
+		fmt.Fprintf(t.grawler.out(), "Could not retry %s: %v\n", url, err)
+		return
+	}
+	fmt.Fprintln(t.grawler.out(), "Retrying", url)
+}
+
+// showDetails shows a result with the chain of pages on which its url has been found
+func (t *tui) showDetails(url string) {
+	result, ok := t.grawler.runningRequests.LoadByUrl(url)
+	if !ok {
+		return
+	}
+
+	var details strings.Builder
+	fmt.Fprintf(&details, "Url:         %s\n", result.url)
+	fmt.Fprintf(&details, "Status:      %d %s\n", result.statusCode, result.status)
+	fmt.Fprintf(&details, "Duration:    %s\n", result.GetDuration().Round(time.Millisecond))
+	fmt.Fprintf(&details, "Depth:       %d\n", result.depth)
+	fmt.Fprintf(&details, "Attempts:    %d\n", result.GetAttempts())
+	if result.HasRedirects() {
+		fmt.Fprintf(&details, "Redirects:   %s\n", result.GetRedirectChain())
+	}
+	if result.error != nil {
+		fmt.Fprintf(&details, "Error:       %s\n", result.error)
+	}
+
+	details.WriteString("\nFound on:\n")
+	for i, foundOn := range t.grawler.foundOnChain(result.initialRequestUrl)[1:] {
+		status := "-"
+		if foundOnResult, ok := t.grawler.runningRequests.LoadByUrl(foundOn); ok && !t.grawler.runningRequests.IsRunning(foundOn) {
+			status = strconv.Itoa(foundOnResult.statusCode)
+		}
+		fmt.Fprintf(&details, "%s%s %s\n", strings.Repeat("  ", i), status, foundOn)
+	}
+
+	text := tview.NewTextView().SetText(details.String()).SetScrollable(true).SetWrap(true)
+	text.SetBorder(true).SetTitle(" Details ([Esc] close) ")
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(text, 0, 4, true).
+			AddItem(nil, 0, 1, false), 0, 6, true).
+		AddItem(nil, 0, 1, false)
+	t.detailsOf = t.focusedPane()
+	t.pages.AddPage(tuiPageDetails, modal, true, true)
+}
+
+// closeDetails closes the details and focuses the pane of the result again
+func (t *tui) closeDetails() {
+	t.pages.RemovePage(tuiPageDetails)
+	t.app.SetFocus(t.detailsOf)
+}
+
+// foundOnChain returns the url followed by the pages on which it has been found, up to the start url
+func (g *Grawler) foundOnChain(url string) []string {
+	chain := []string{url}
+	seen := map[string]bool{url: true}
+	for foundOn := g.runningRequests.GetFoundUrl(url); foundOn != "" && !seen[foundOn]; foundOn = g.runningRequests.GetFoundUrl(foundOn) {
+		chain = append(chain, foundOn)
+		seen[foundOn] = true
+	}
+	return chain
+}
+
+// add adds a finished result to the results pane
+func (t *tui) add(result *Result) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.rows = append(t.rows, result)
+	if len(t.rows) > tuiMaxResults {
+		t.rows = t.rows[len(t.rows)-tuiMaxResults:]
+	}
+	t.rowsChanged = true
+}
+
+// addLog adds a line of the captured output to the log pane. After the tui has been closed the output is
+// printed again.
+func (t *tui) addLog(line string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.exited {
+		_, _ = fmt.Fprintln(t.stdout, line)
+		return
+	}
+
+	t.logLines = append(t.logLines, tview.TranslateANSI(tview.Escape(line)))
+	if len(t.logLines) > tuiMaxLogLines {
+		t.logLines = t.logLines[len(t.logLines)-tuiMaxLogLines:]
+	}
+}
+
+// refresh updates all panes, it is called by the event loop of the app. The panes of the results and the
+// requests are only rebuilt when their data has changed, so large grawlings are not sorted on every tick.
+func (t *tui) refresh() {
+	t.mutex.Lock()
+	var rows []*Result
+	rowsChanged := t.rowsChanged
+	if rowsChanged {
+		rows = make([]*Result, len(t.rows))
+		copy(rows, t.rows)
+		t.rowsChanged = false
+	}
+	logText := strings.Join(t.logLines, "\n")
+	filter := tuiFilters[t.filter]
+	finished := t.finished
+	t.mutex.Unlock()
+
+	if rowsChanged {
+		t.refreshResults(rows, filter.name, filter.match)
+	}
+	if version := t.grawler.runningRequests.Version(); !t.requestsShown || version != t.requestsVersion {
+		t.requestsVersion = version
+		t.requestsShown = true
+		t.refreshRequests()
+	}
+
+	if logText != t.log.GetText(false) {
+		t.log.SetText(logText).ScrollToEnd()
+	}
+
+	_, _, width, _ := t.status.GetInnerRect()
+	state := ""
+	switch {
+	case finished && t.grawler.isStopping():
+		state = "[yellow::b]Stopped[-::-] "
+	case finished:
+		state = "[green::b]Finished, press q to exit[-::-] "
+	case t.grawler.pause.isPaused():
+		state = "[yellow::b]Paused[-::-] "
+	}
+	t.status.SetText(state + strings.TrimRight(t.grawler.statusBar.line(max(width, 1)), " ") + "\n" + tview.Escape(tuiHelp))
+}
+
+// refreshResults rebuilds the results pane with the newest results which match the filter
+func (t *tui) refreshResults(rows []*Result, filterName string, match func(result *Result) bool) {
+	var resultRows []tuiRow
+	for i := len(rows) - 1; i >= 0 && len(resultRows) < tuiMaxRows; i-- {
+		result := rows[i]
+		if !match(result) {
+			continue
+		}
+		resultRows = append(resultRows, tuiRow{
+			key:   result.initialRequestUrl,
+			color: resultColor(result),
+			cells: []string{
+				strconv.Itoa(result.statusCode),
+				StatusAbbreviation(result.statusCode),
+				fmt.Sprintf("%dms", result.GetDuration().Milliseconds()),
+				result.url,
+			},
+		})
+	}
+	t.results.setRows(resultRows)
+	t.results.SetTitle(fmt.Sprintf(" Results (%s) ", filterName))
+}
+
+// refreshRequests rebuilds the errors, hosts and frontier panes from the running requests
+func (t *tui) refreshRequests() {
+	results := *t.grawler.runningRequests.GetValues()
+	var errorRows []tuiRow
+	errorCount := 0
+	hostErrors := map[string]int{}
+	for _, result := range results {
+		if !result.HasError() {
+			continue
+		}
+		errorCount++
+		hostErrors[result.urlHost]++
+		if len(errorRows) < tuiMaxRows {
+			errorRows = append(errorRows, tuiRow{
+				key:   result.initialRequestUrl,
+				color: tcell.ColorRed,
+				cells: []string{strconv.Itoa(result.statusCode), result.url, result.foundOnUrl},
+			})
+		}
+	}
+	t.errors.setRows(errorRows)
+	t.errors.SetTitle(fmt.Sprintf(" Errors (%d) ", errorCount))
+
+	hosts, hostStats := groupedDurationStats(results, func(result *Result) string {
+		return result.urlHost
+	})
+	hostRows := make([]tuiRow, 0, len(hosts))
+	for _, host := range hosts {
+		stats := hostStats[host]
+		hostColor := tcell.ColorGreen
+		if hostErrors[host] > 0 {
+			hostColor = tcell.ColorRed
+		}
+		hostRows = append(hostRows, tuiRow{
+			key:   host,
+			color: hostColor,
+			cells: []string{
+				host,
+				strconv.Itoa(stats.count()),
+				strconv.Itoa(hostErrors[host]),
+				stats.avg.Round(time.Millisecond).String(),
+				stats.percentile(90).Round(time.Millisecond).String(),
+			},
+		})
+	}
+	t.hosts.setRows(hostRows)
+
+	queued := t.grawler.runningRequests.QueuedUrls()
+	frontierRows := make([]tuiRow, 0, min(len(queued), tuiMaxRows))
+	for _, queuedUrl := range queued[:min(len(queued), tuiMaxRows)] {
+		state := "queued"
+		if t.grawler.runningRequests.IsRunning(queuedUrl.Url) {
+			state = "running"
+		}
+		frontierRows = append(frontierRows, tuiRow{
+			key:   queuedUrl.Url,
+			color: tcell.ColorWhite,
+			cells: []string{state, strconv.Itoa(queuedUrl.Depth), queuedUrl.Url},
+		})
+	}
+	t.frontier.setRows(frontierRows)
+	t.frontier.SetTitle(fmt.Sprintf(" Frontier (%d) ", len(queued)))
+}
+
+func resultColor(result *Result) tcell.Color {
+	switch {
+	case result.IsRedirected():
+		return tcell.ColorYellow
+	case result.HasError():
+		return tcell.ColorRed
+	default:
+		return tcell.ColorGreen
+	}
+}
+
+// captureStdout redirects the output to the log pane. The tui itself writes to the terminal device.
+func (t *tui) captureStdout() error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	t.stdout = os.Stdout
+	t.colorOutput = color.Output
+	t.pipe = writer
+	t.captured = make(chan struct{})
+	os.Stdout = writer
+	color.Output = writer
+
+	go func() {
+		defer close(t.captured)
+		defer reader.Close()
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			t.addLog(scanner.Text())
+		}
+		// Do not block the writers on too long lines
+		_, _ = io.Copy(t.stdout, reader)
+	}()
+	return nil
+}
+
+// close restores the output after the tui has been closed. With waitForUser the finished grawling is
+// shown until the user quits.
+func (t *tui) close(waitForUser bool) {
+	t.mutex.Lock()
+	t.finished = true
+	t.mutex.Unlock()
+
+	if !waitForUser {
+		t.quitOnce.Do(func() {
+			close(t.quit)
+		})
+	}
+	<-t.done
+
+	os.Stdout = t.stdout
+	color.Output = t.colorOutput
+	_ = t.pipe.Close()
+	<-t.captured
+}
@@ -0,0 +1,79 @@
+package grawler
+
+import (
+	"github.com/robole-dev/grawler/internal/grawl"
+	"time"
+)
+
+// EventType is the kind of an Event
+type EventType string
+
+const (
+	// EventRequest is emitted when a request is started
+	EventRequest = EventType(grawl.EventRequest)
+	// EventResponse is emitted when the response of a url has been processed without error
+	EventResponse = EventType(grawl.EventResponse)
+	// EventRedirect is emitted when a redirect is followed
+	EventRedirect = EventType(grawl.EventRedirect)
+	// EventError is emitted when a url failed, e.g. with an error status code or without a response
+	EventError = EventType(grawl.EventError)
+	// EventFinished is emitted once after all requests have finished or the grawling has been stopped
+	EventFinished = EventType(grawl.EventFinished)
+)
+
+// Event describes a step of the grawling. Url is the requested url, From the url it was found on or redirected from.
+// Result is set for responses and errors. Err is set for errors without a response and for the finished event
+// of a canceled grawling or a grawling truncated by a budget.
+type Event struct {
+	Type   EventType
+	Url    string
+	From   string
+	Result *Result
+	Err    error
+}
+
+// Result is the outcome of a grawled url
+type Result struct {
+	// Url is the url of the response, the last url of the redirects
+	Url string
+	// InitialUrl is the requested url before any redirects
+	InitialUrl string
+	// FoundOnUrl is the url of the page which links the url
+	FoundOnUrl string
+	// StatusCode is 0 if the request failed without a response
+	StatusCode    int
+	ContentType   string
+	Depth         int
+	Duration      time.Duration
+	RedirectChain string
+	Attempts      int
+	// HasError is true for errors without a response and for the status codes of Config.ResponseErrorCodes
+	HasError bool
+	Err      error
+}
+
+func newEvent(event grawl.Event) Event {
+	converted := Event{
+		Type: EventType(event.Type),
+		Url:  event.Url,
+		From: event.From,
+		Err:  event.Err,
+	}
+
+	if event.Result != nil {
+		converted.Result = &Result{
+			Url:           event.Result.GetUrl(),
+			InitialUrl:    event.Result.GetInitialUrl(),
+			FoundOnUrl:    event.Result.GetFoundOnUrl(),
+			StatusCode:    event.Result.GetStatusCode(),
+			ContentType:   event.Result.GetContentType(),
+			Depth:         event.Result.GetDepth(),
+			Duration:      event.Result.GetDuration(),
+			RedirectChain: event.Result.GetRedirectChain(),
+			Attempts:      event.Result.GetAttempts(),
+			HasError:      event.Result.HasError(),
+			Err:           event.Result.GetError(),
+		}
+	}
+	return converted
+}
@@ -0,0 +1,114 @@
+package grawler
+
+import (
+	"context"
+	"errors"
+	"github.com/robole-dev/grawler/internal/grawl"
+	"slices"
+	"testing"
+)
+
+func TestNewEvent(t *testing.T) {
+	canceled := grawl.Event{Type: grawl.EventFinished, Url: "https://example.com/", Err: context.Canceled}
+	event := newEvent(canceled)
+
+	if event.Type != EventFinished || event.Url != canceled.Url || !errors.Is(event.Err, context.Canceled) {
+		t.Errorf("expected %+v, got %+v", canceled, event)
+	}
+	if event.Result != nil {
+		t.Errorf("expected no result, got %+v", event.Result)
+	}
+}
+
+func TestCrawlEvents(t *testing.T) {
+	server := newTestServer(t)
+	recorder := &eventRecorder{}
+
+	if err := Crawl(context.Background(), server.URL+"/", Config{OnEvent: recorder.add}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedRequests := []string{
+		server.URL + "/", server.URL + "/deep", server.URL + "/missing", server.URL + "/moved", server.URL + "/page",
+	}
+	if requests := recorder.urls(EventRequest); !slices.Equal(requests, expectedRequests) {
+		t.Errorf("expected requests %v, got %v", expectedRequests, requests)
+	}
+	if redirects := recorder.urls(EventRedirect); !slices.Equal(redirects, []string{server.URL + "/target"}) {
+		t.Errorf("expected the redirect to /target, got %v", redirects)
+	}
+
+	results := map[string]*Result{}
+	for _, event := range recorder.events {
+		if event.Result != nil {
+			results[event.Url] = event.Result
+		}
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		expected Result
+	}{
+		{
+			name: "page",
+			url:  server.URL + "/deep",
+			expected: Result{
+				Url:         server.URL + "/deep",
+				InitialUrl:  server.URL + "/deep",
+				FoundOnUrl:  server.URL + "/page",
+				StatusCode:  200,
+				ContentType: "text/html",
+				Depth:       3,
+				Attempts:    1,
+			},
+		},
+		{
+			name: "error status code",
+			url:  server.URL + "/missing",
+			expected: Result{
+				Url:         server.URL + "/missing",
+				InitialUrl:  server.URL + "/missing",
+				FoundOnUrl:  server.URL + "/",
+				StatusCode:  404,
+				ContentType: "text/plain; charset=utf-8",
+				Depth:       2,
+				Attempts:    1,
+				HasError:    true,
+			},
+		},
+		{
+			name: "redirect",
+			url:  server.URL + "/target",
+			expected: Result{
+				Url:           server.URL + "/target",
+				InitialUrl:    server.URL + "/moved",
+				FoundOnUrl:    server.URL + "/",
+				StatusCode:    200,
+				ContentType:   "text/html",
+				Depth:         2,
+				RedirectChain: "301 " + server.URL + "/moved -> 200 " + server.URL + "/target",
+				Attempts:      1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := results[test.url]
+			if !ok {
+				t.Fatalf("expected a result for %s", test.url)
+			}
+
+			// The duration depends on the server
+			converted := *result
+			converted.Duration = 0
+			if converted != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, converted)
+			}
+			if result.Duration <= 0 {
+				t.Errorf("expected a duration, got %v", result.Duration)
+			}
+		})
+	}
+}
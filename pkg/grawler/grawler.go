@@ -0,0 +1,351 @@
+// Package grawler embeds the grawling of websites into Go programs. It grawls the links of a start url like the
+// grawler command, but prints nothing and reports each step as an Event instead:
+//
+//	crawler, err := grawler.New(grawler.Config{
+//		Parallel: grawler.Ptr(4),
+//		OnEvent: func(event grawler.Event) {
+//			if event.Type == grawler.EventError {
+//				fmt.Println(event.Result.StatusCode, event.Url)
+//			}
+//		},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = crawler.Crawl(ctx, "https://example.com")
+//
+// The grawling stops when the context is canceled. Running requests are finished before Crawl returns.
+//
+// Run, Coverage and State.Resume grawl like the commands of the grawler command instead: they print the results
+// and the summary, write the reports, prompt for input and stop on interrupt signals.
+package grawler
+
+import (
+	"context"
+	"github.com/robole-dev/grawler/internal/grawl"
+	"io"
+	"time"
+)
+
+// ExitCode is the exit code of the grawler command for the outcome of a grawling
+type ExitCode = grawl.ExitCode
+
+const (
+	ExitCodeOk             = grawl.ExitCodeOk
+	ExitCodeErrorsFound    = grawl.ExitCodeErrorsFound
+	ExitCodeConfigError    = grawl.ExitCodeConfigError
+	ExitCodeStartUrlFailed = grawl.ExitCodeStartUrlFailed
+	ExitCodeInterrupted    = grawl.ExitCodeInterrupted
+)
+
+// LimitRule limits the parallel requests and the delays in milliseconds for the hosts matching the domain glob.
+// Values which are not set are taken from Config.Parallel, Config.Delay and Config.RandomDelay.
+type LimitRule = grawl.LimitRule
+
+const (
+	// JunitGroupByHost groups the test cases of the JUnit report by the host of the urls
+	JunitGroupByHost = grawl.JunitGroupByHost
+	// JunitGroupByFoundOn groups the test cases of the JUnit report by the url they were found on
+	JunitGroupByFoundOn = grawl.JunitGroupByFoundOn
+)
+
+var (
+	// DefaultFollowElements are the element types whose urls are grawled and searched for further links by default
+	DefaultFollowElements = grawl.DefaultFollowElements
+	// DefaultRetryOn are the failures which are retried by default
+	DefaultRetryOn = grawl.DefaultRetryOn
+	// DefaultCacheHeaders are the response headers which are saved by default with Config.Warm
+	DefaultCacheHeaders = grawl.DefaultCacheHeaders
+)
+
+// ElementTypes returns the element types of Config.FollowElements and Config.CheckElements
+func ElementTypes() []string {
+	return grawl.LinkExtractorNames()
+}
+
+// Config configures the grawling. The zero value grawls with the defaults of the grawler command. The fields with
+// pointers are not set by nil, so a set 0 is used like the 0 of the flag, e.g. Ptr(time.Duration(0)) for no timeout.
+type Config struct {
+	// Parallel is the number of parallel requests, default 1. 0 does not limit the parallel requests.
+	Parallel *int
+	// Delay is the delay between requests
+	Delay time.Duration
+	// RandomDelay is the max random delay between requests
+	RandomDelay time.Duration
+	// Limits are the limits of other hosts, the first rule matching a host is used
+	Limits []LimitRule
+	// Adaptive slows down per host on 429 and 503 responses, errors and slow responses
+	Adaptive bool
+	// AdaptiveMaxDelay is the max delay of the adaptive throttling, default 30s
+	AdaptiveMaxDelay *time.Duration
+	// MaxDepth limits the recursion depth, 0 for infinite recursion
+	MaxDepth int
+	// MaxRedirects is the max number of redirects to follow per url, default 10
+	MaxRedirects int
+	// NoFollowRedirects reports redirect responses with their own status code instead of following them
+	NoFollowRedirects bool
+	// VisitRedirectTargets grawls the locations of the redirects which are not followed
+	VisitRedirectTargets bool
+	// UserAgent is the user agent of the requests, default "grawler"
+	UserAgent string
+	// Username and Password are used for HTTP Basic Authentication
+	Username string
+	Password string
+	// AllowedDomains are grawled in addition to the domain of the start url
+	AllowedDomains []string
+	// URLFilters are regular expressions, only matching urls are visited
+	URLFilters []string
+	// DisallowedURLFilters are regular expressions, matching urls are not visited
+	DisallowedURLFilters []string
+	// RespectRobotsTxt skips the urls disallowed by the robots.txt
+	RespectRobotsTxt bool
+	// RespectNofollow skips the links with rel="nofollow"
+	RespectNofollow bool
+	// Path restricts the grawling to urls under this path
+	Path string
+	// Sitemap grawls the urls of the sitemap, the start url is the sitemap.xml or the root of the website
+	Sitemap bool
+	// FollowElements are the element types whose urls are grawled and searched for further links, default "a"
+	FollowElements []string
+	// CheckElements are the element types whose urls are only requested, e.g. "img", "script" or "stylesheet"
+	CheckElements []string
+	// CheckAll checks the urls of all element types which are not followed
+	CheckAll bool
+	// RequestTimeout is the timeout to wait for a response, default 10s. 0 waits without timeout.
+	RequestTimeout *time.Duration
+	// Retries is the number of retries of requests which failed transiently, see RetryOn
+	Retries int
+	// RetryDelay is the delay before the first retry, default 1s. It is doubled for each further retry.
+	RetryDelay *time.Duration
+	// RetryOn are the failures which are retried: "timeout", "connection" and status codes or ranges like "502-504"
+	RetryOn []string
+	// ResponseErrorCodes are the status codes or ranges which are errors, default "400-599"
+	ResponseErrorCodes []string
+	// StopOnError stops the grawling on the first error
+	StopOnError bool
+	// OutputFile is the csv or jsonl file the results are written to
+	OutputFile string
+	// OutputFormat is "csv" or "jsonl", by default it is taken from the extension of OutputFile
+	OutputFormat string
+	// Warm saves the cache headers of the responses, WarmTwice requests each url a second time with a warm cache
+	Warm      bool
+	WarmTwice bool
+	// CacheHeaders are the response headers which are saved with Warm, default DefaultCacheHeaders
+	CacheHeaders []string
+	// ShutdownTimeout is the time running requests get to finish after the context is canceled, default 10s
+	ShutdownTimeout *time.Duration
+	// MaxDuration, MaxRequests and MaxErrors are budgets which stop the grawling when they are used up, 0 for no
+	// limit. The finished event of a truncated grawling has the exhausted budget as error.
+	MaxDuration time.Duration
+	MaxRequests int
+	MaxErrors   int
+	// OnEvent is called for each event of the grawling, one event after another
+	OnEvent func(Event)
+
+	// The following fields are only used by Run, Coverage and State.Resume
+
+	// Output is the writer of the results and the summary, default stdout
+	Output io.Writer
+	// FailThreshold is the number or the percentage of errors, e.g. "5%", which fail the grawling, default "1".
+	// "0" never fails.
+	FailThreshold string
+	// PauseOnError pauses the grawling on errors and asks on stdin how to go on
+	PauseOnError bool
+	// Slowest is the number of the slowest urls in the summary
+	Slowest int
+	// StatusBar shows a live status bar in the last line of the terminal
+	StatusBar bool
+	// Tui shows an interactive terminal ui instead of the rows of the results
+	Tui bool
+	// JunitReport is the file of a JUnit XML report, its test suites are grouped by JunitGroupBy, default by host
+	JunitReport  string
+	JunitGroupBy string
+	// HtmlReport is the file of a html report with statistics and all results
+	HtmlReport string
+	// WriteSitemap is the file of a sitemap with the grawled html pages. SitemapLastmod adds the Last-Modified
+	// headers, the sitemaps of a sitemap index are linked under SitemapBaseUrl, default the root of the website.
+	WriteSitemap   string
+	SitemapLastmod bool
+	SitemapBaseUrl string
+	// CoverageReport is the csv file of the pages compared by Coverage
+	CoverageReport string
+	// StateDir is the directory the state is saved to every StateInterval and on interrupt, default 30s.
+	// Resume the grawling with LoadState.
+	StateDir      string
+	StateInterval time.Duration
+}
+
+// Crawler grawls websites with a Config. A Crawler may be used for several grawlings, also at the same time.
+type Crawler struct {
+	config Config
+	flags  grawl.Flags
+}
+
+// New checks the config and returns a Crawler
+func New(config Config) (*Crawler, error) {
+	crawler := &Crawler{config: config, flags: config.flags()}
+	if _, err := grawl.NewGrawler(crawler.flags); err != nil {
+		return nil, err
+	}
+	return crawler, nil
+}
+
+// Crawl grawls the start url and blocks until all requests are finished or the context is canceled.
+// It returns the error of the context if the grawling has been canceled.
+func (c *Crawler) Crawl(ctx context.Context, startURL string) error {
+	flags := c.flags
+	// Crawl prints nothing and does not ask for input
+	flags.FlagPauseOnError = false
+	flags.FlagTui = false
+	flags.FlagNoStatusBar = true
+
+	grawler, err := grawl.NewGrawler(flags)
+	if err != nil {
+		return err
+	}
+
+	grawler.SetOutput(io.Discard)
+	c.registerEvents(grawler)
+	return grawler.Crawl(ctx, startURL)
+}
+
+// Run grawls the start url like the grawl command. It prints the results and the summary, writes the output file,
+// the reports and the state, and stops on the first interrupt signal.
+func (c *Crawler) Run(startURL string) ExitCode {
+	grawler, err := c.newGrawler()
+	if err != nil {
+		return ExitCodeConfigError
+	}
+	return grawler.Grawl(startURL)
+}
+
+// Coverage grawls the sitemap and the links of the website like the grawl command with --coverage and prints
+// the pages which are only in the sitemap or only found by links
+func (c *Crawler) Coverage(startURL string) ExitCode {
+	grawler, err := c.newGrawler()
+	if err != nil {
+		return ExitCodeConfigError
+	}
+	return grawler.Coverage(startURL)
+}
+
+func (c *Crawler) newGrawler() (*grawl.Grawler, error) {
+	grawler, err := grawl.NewGrawler(c.flags)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.config.Output != nil {
+		grawler.SetOutput(c.config.Output)
+	}
+	c.registerEvents(grawler)
+	return grawler, nil
+}
+
+func (c *Crawler) registerEvents(grawler *grawl.Grawler) {
+	if c.config.OnEvent == nil {
+		return
+	}
+	grawler.OnEvent(func(event grawl.Event) {
+		c.config.OnEvent(newEvent(event))
+	})
+}
+
+// Crawl grawls the start url with the config, see Crawler.Crawl
+func Crawl(ctx context.Context, startURL string, config Config) error {
+	crawler, err := New(config)
+	if err != nil {
+		return err
+	}
+	return crawler.Crawl(ctx, startURL)
+}
+
+// EffectiveLimitRules returns the limits with the values taken from Parallel, Delay and RandomDelay and the rule
+// for all other hosts at the end
+func (c Config) EffectiveLimitRules() ([]LimitRule, error) {
+	return grawl.EffectiveLimitRules(c.flags())
+}
+
+func (c Config) flags() grawl.Flags {
+	return grawl.Flags{
+		FlagParallel:             pointerOr(c.Parallel, 1),
+		FlagDelay:                c.Delay.Milliseconds(),
+		FlagRandomDelay:          c.RandomDelay.Milliseconds(),
+		FlagLimitRules:           c.Limits,
+		FlagAdaptive:             c.Adaptive,
+		FlagAdaptiveMaxDelay:     pointerOr(c.AdaptiveMaxDelay, 30*time.Second).Milliseconds(),
+		FlagMaxDepth:             c.MaxDepth,
+		FlagMaxRedirects:         valueOr(c.MaxRedirects, 10),
+		FlagNoFollowRedirects:    c.NoFollowRedirects,
+		FlagVisitRedirectTargets: c.VisitRedirectTargets,
+		FlagOutputFilename:       c.OutputFile,
+		FlagOutputFormat:         c.OutputFormat,
+		FlagUsername:             c.Username,
+		FlagPassword:             c.Password,
+		FlagUserAgent:            valueOr(c.UserAgent, "grawler"),
+		FlagSitemap:              c.Sitemap,
+		FlagWarm:                 c.Warm,
+		FlagWarmTwice:            c.WarmTwice,
+		FlagCacheHeaders:         sliceOr(c.CacheHeaders, grawl.DefaultCacheHeaders),
+		FlagCoverageReport:       c.CoverageReport,
+		FlagAllowedDomains:       c.AllowedDomains,
+		FlagRespectRobotsTxt:     c.RespectRobotsTxt,
+		FlagRespectNofollow:      c.RespectNofollow,
+		FlagPath:                 c.Path,
+		FlagCheckAll:             c.CheckAll,
+		FlagFollowElements:       sliceOr(c.FollowElements, grawl.DefaultFollowElements),
+		FlagCheckElements:        c.CheckElements,
+		FlagRequestTimeout:       float32(pointerOr(c.RequestTimeout, 10*time.Second).Seconds()),
+		FlagRetries:              c.Retries,
+		FlagRetryDelay:           pointerOr(c.RetryDelay, time.Second).Milliseconds(),
+		FlagRetryOn:              sliceOr(c.RetryOn, grawl.DefaultRetryOn),
+		FlagDisallowedURLFilters: c.DisallowedURLFilters,
+		FlagURLFilters:           c.URLFilters,
+		FlagStopOnError:          c.StopOnError,
+		FlagPauseOnError:         c.PauseOnError,
+		FlagResponseErrorCodes:   sliceOr(c.ResponseErrorCodes, []string{"400-599"}),
+		FlagFailThreshold:        valueOr(c.FailThreshold, "1"),
+		FlagJunitReport:          c.JunitReport,
+		FlagJunitGroupBy:         valueOr(c.JunitGroupBy, grawl.JunitGroupByHost),
+		FlagHtmlReport:           c.HtmlReport,
+		FlagSlowest:              c.Slowest,
+		FlagNoStatusBar:          !c.StatusBar,
+		FlagTui:                  c.Tui,
+		FlagWriteSitemap:         c.WriteSitemap,
+		FlagSitemapLastmod:       c.SitemapLastmod,
+		FlagSitemapBaseUrl:       c.SitemapBaseUrl,
+		FlagStateDir:             c.StateDir,
+		FlagStateInterval:        int(valueOr(c.StateInterval, 30*time.Second).Seconds()),
+		FlagShutdownTimeout:      float32(pointerOr(c.ShutdownTimeout, 10*time.Second).Seconds()),
+		FlagMaxDuration:          c.MaxDuration,
+		FlagMaxRequests:          c.MaxRequests,
+		FlagMaxErrors:            c.MaxErrors,
+	}
+}
+
+// Ptr returns a pointer to the value, e.g. for the fields of Config and LimitRule which may be unset
+func Ptr[T any](value T) *T {
+	return &value
+}
+
+func pointerOr[T any](value *T, defaultValue T) T {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
+func valueOr[T comparable](value T, defaultValue T) T {
+	var zero T
+	if value == zero {
+		return defaultValue
+	}
+	return value
+}
+
+func sliceOr(values []string, defaultValues []string) []string {
+	if len(values) == 0 {
+		return defaultValues
+	}
+	return values
+}
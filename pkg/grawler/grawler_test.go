@@ -0,0 +1,258 @@
+package grawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer serves a website whose home page links /page, the missing /missing and /moved, which redirects
+// to /target. /page links /deep.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	writeHtml := func(w http.ResponseWriter, body string) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>"+body+"</body></html>")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeHtml(w, `<a href="/page">page</a><a href="/missing">missing</a><a href="/moved">moved</a>`)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		writeHtml(w, `<a href="/deep">deep</a>`)
+	})
+	for _, page := range []string{"/deep", "/target"} {
+		mux.HandleFunc(page, func(w http.ResponseWriter, r *http.Request) {
+			writeHtml(w, "")
+		})
+	}
+	mux.Handle("/moved", http.RedirectHandler("/target", http.StatusMovedPermanently))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// eventRecorder collects the events of a grawling
+type eventRecorder struct {
+	sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) add(event Event) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, event)
+}
+
+// urls returns the sorted urls of the events of the type
+func (r *eventRecorder) urls(eventType EventType) []string {
+	r.Lock()
+	defer r.Unlock()
+
+	urls := make([]string, 0)
+	for _, event := range r.events {
+		if event.Type == eventType {
+			urls = append(urls, event.Url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func (r *eventRecorder) last() Event {
+	r.Lock()
+	defer r.Unlock()
+	return r.events[len(r.events)-1]
+}
+
+func TestConfigFlags(t *testing.T) {
+	tests := []struct {
+		name                   string
+		config                 Config
+		expectedParallel       int
+		expectedRequestTimeout float32
+		expectedRetryDelay     int64
+		expectedShutdown       float32
+		expectedAdaptiveDelay  int64
+		expectedUserAgent      string
+	}{
+		{
+			name:                   "defaults",
+			config:                 Config{},
+			expectedParallel:       1,
+			expectedRequestTimeout: 10,
+			expectedRetryDelay:     1000,
+			expectedShutdown:       10,
+			expectedAdaptiveDelay:  30000,
+			expectedUserAgent:      "grawler",
+		},
+		{
+			name: "zero values",
+			config: Config{
+				Parallel:         Ptr(0),
+				RequestTimeout:   Ptr(time.Duration(0)),
+				RetryDelay:       Ptr(time.Duration(0)),
+				ShutdownTimeout:  Ptr(time.Duration(0)),
+				AdaptiveMaxDelay: Ptr(time.Duration(0)),
+			},
+			expectedUserAgent: "grawler",
+		},
+		{
+			name: "values",
+			config: Config{
+				Parallel:         Ptr(4),
+				RequestTimeout:   Ptr(1500 * time.Millisecond),
+				RetryDelay:       Ptr(2 * time.Second),
+				ShutdownTimeout:  Ptr(3 * time.Second),
+				AdaptiveMaxDelay: Ptr(5 * time.Second),
+				UserAgent:        "test",
+			},
+			expectedParallel:       4,
+			expectedRequestTimeout: 1.5,
+			expectedRetryDelay:     2000,
+			expectedShutdown:       3,
+			expectedAdaptiveDelay:  5000,
+			expectedUserAgent:      "test",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := test.config.flags()
+			if flags.FlagParallel != test.expectedParallel {
+				t.Errorf("expected parallel %v, got %v", test.expectedParallel, flags.FlagParallel)
+			}
+			if flags.FlagRequestTimeout != test.expectedRequestTimeout {
+				t.Errorf("expected request timeout %v, got %v", test.expectedRequestTimeout, flags.FlagRequestTimeout)
+			}
+			if flags.FlagRetryDelay != test.expectedRetryDelay {
+				t.Errorf("expected retry delay %v, got %v", test.expectedRetryDelay, flags.FlagRetryDelay)
+			}
+			if flags.FlagShutdownTimeout != test.expectedShutdown {
+				t.Errorf("expected shutdown timeout %v, got %v", test.expectedShutdown, flags.FlagShutdownTimeout)
+			}
+			if flags.FlagAdaptiveMaxDelay != test.expectedAdaptiveDelay {
+				t.Errorf("expected adaptive max delay %v, got %v", test.expectedAdaptiveDelay, flags.FlagAdaptiveMaxDelay)
+			}
+			if flags.FlagUserAgent != test.expectedUserAgent {
+				t.Errorf("expected user agent %v, got %v", test.expectedUserAgent, flags.FlagUserAgent)
+			}
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "fail threshold", config: Config{FailThreshold: "many"}},
+		{name: "response error codes", config: Config{ResponseErrorCodes: []string{"600"}}},
+		{name: "element type", config: Config{FollowElements: []string{"unknown"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(test.config); err == nil {
+				t.Errorf("expected an error for %+v", test.config)
+			}
+		})
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name              string
+		config            Config
+		cancel            bool
+		expectedResponses []string
+		expectedErrors    []string
+		expectedErr       error
+	}{
+		{
+			name:              "all pages",
+			expectedResponses: []string{server.URL + "/", server.URL + "/deep", server.URL + "/page", server.URL + "/target"},
+			expectedErrors:    []string{server.URL + "/missing"},
+		},
+		{
+			name:              "max depth",
+			config:            Config{MaxDepth: 1},
+			expectedResponses: []string{server.URL + "/"},
+			expectedErrors:    []string{},
+		},
+		{
+			name:        "canceled",
+			cancel:      true,
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &eventRecorder{}
+			test.config.Parallel = Ptr(2)
+			test.config.OnEvent = recorder.add
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+
+			err := Crawl(ctx, server.URL+"/", test.config)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expected %v, got %v", test.expectedErr, err)
+			}
+
+			if test.cancel {
+				if finished := recorder.last(); finished.Type != EventFinished || !errors.Is(finished.Err, context.Canceled) {
+					t.Errorf("expected a canceled finished event, got %+v", finished)
+				}
+				return
+			}
+
+			if responses := recorder.urls(EventResponse); !slices.Equal(responses, test.expectedResponses) {
+				t.Errorf("expected responses %v, got %v", test.expectedResponses, responses)
+			}
+			if errorUrls := recorder.urls(EventError); !slices.Equal(errorUrls, test.expectedErrors) {
+				t.Errorf("expected errors %v, got %v", test.expectedErrors, errorUrls)
+			}
+			if finished := recorder.last(); finished.Type != EventFinished || finished.Err != nil {
+				t.Errorf("expected the finished event last, got %+v", finished)
+			}
+		})
+	}
+}
+
+func TestCrawlErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "output file in a missing directory", config: Config{OutputFile: filepath.Join(t.TempDir(), "missing", "results.csv")}},
+		{name: "url filter", config: Config{URLFilters: []string{"("}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Crawl(context.Background(), server.URL+"/", test.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
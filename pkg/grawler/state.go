@@ -0,0 +1,45 @@
+package grawler
+
+import (
+	"github.com/robole-dev/grawler/internal/grawl"
+	"io"
+)
+
+// State is the saved state of a grawling which has been run with Config.StateDir
+type State struct {
+	// StartURL is the url the grawling has been started with
+	StartURL string
+	// StateDir is the directory the state has been loaded from, the resumed grawling keeps saving to it
+	StateDir string
+	// SavedAt is the time the state has been saved, formatted as RFC 3339
+	SavedAt string
+	state   *grawl.State
+}
+
+// LoadState reads the state of an interrupted grawling from the state directory
+func LoadState(stateDir string) (*State, error) {
+	state, err := grawl.LoadState(stateDir)
+	if err != nil {
+		return nil, err
+	}
+	return &State{
+		StartURL: state.GrawlUrl,
+		StateDir: state.Flags.FlagStateDir,
+		SavedAt:  state.SavedAt,
+		state:    state,
+	}, nil
+}
+
+// Resume continues the grawling with the config it has been started with, see Crawler.Run. The results and
+// the summary are written to the output, nil for stdout.
+func (s *State) Resume(output io.Writer) (ExitCode, error) {
+	grawler, err := grawl.NewGrawler(s.state.Flags)
+	if err != nil {
+		return ExitCodeConfigError, err
+	}
+
+	if output != nil {
+		grawler.SetOutput(output)
+	}
+	return grawler.Resume(s.state), nil
+}
@@ -0,0 +1,70 @@
+package grawler
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadStateAndResume(t *testing.T) {
+	server := newTestServer(t)
+	stateDir := filepath.Join(t.TempDir(), "state")
+
+	output := &bytes.Buffer{}
+	crawler, err := New(Config{
+		MaxRequests:   2,
+		StateDir:      stateDir,
+		FailThreshold: "0",
+		Output:        output,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode := crawler.Run(server.URL + "/"); exitCode != ExitCodeOk {
+		t.Fatalf("expected exit code %d, got %d: %s", ExitCodeOk, exitCode, output)
+	}
+
+	state, err := LoadState(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.StartURL != server.URL+"/" {
+		t.Errorf("expected start url %s, got %s", server.URL+"/", state.StartURL)
+	}
+	if state.StateDir != stateDir {
+		t.Errorf("expected state dir %s, got %s", stateDir, state.StateDir)
+	}
+	if state.SavedAt == "" {
+		t.Error("expected the time the state has been saved")
+	}
+
+	// The budget is saved with the state, so each resumed grawling sends 2 requests
+	allOutput := output.String()
+	for range 4 {
+		output.Reset()
+		if exitCode, err := state.Resume(output); err != nil || exitCode != ExitCodeOk {
+			t.Fatalf("expected exit code %d, got %d and %v: %s", ExitCodeOk, exitCode, err, output)
+		}
+		allOutput += output.String()
+		if !strings.Contains(output.String(), "truncated by budget") {
+			break
+		}
+
+		if state, err = LoadState(stateDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, path := range []string{"/deep", "/missing", "/page", "/target"} {
+		if !strings.Contains(allOutput, server.URL+path) {
+			t.Errorf("expected the grawlings to grawl %s, got %s", path, allOutput)
+		}
+	}
+}
+
+func TestLoadStateMissing(t *testing.T) {
+	if _, err := LoadState(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing state")
+	}
+}